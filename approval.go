@@ -0,0 +1,474 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/message"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const (
+	// approvalPath is the path Slack interactivity requests are posted to.
+	approvalPath = "/slack/interactions"
+	// approvalBlockPrefix prefixes the actions block ID of an approval request.
+	approvalBlockPrefix = "relicta_approval_"
+	// reasonBlockPrefix prefixes the reason input block ID of an approval request.
+	reasonBlockPrefix = "relicta_reason_"
+	// maxSignatureAge is how old a signed Slack request may be before it is rejected.
+	maxSignatureAge = 5 * time.Minute
+	// maxInteractionSize caps the size of an interactivity request body.
+	maxInteractionSize = 1 << 20
+	// approvalResponseTimeout bounds a follow-up posted to a response_url.
+	approvalResponseTimeout = 10 * time.Second
+)
+
+// approvalDecision is the outcome of an approval request.
+type approvalDecision struct {
+	Approved bool
+	UserID   string
+	UserName string
+	Reason   string
+}
+
+// interactionPayload is the subset of a Slack block_actions payload used by the gate.
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		BlockID  string `json:"block_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	State struct {
+		Values map[string]map[string]struct {
+			Value string `json:"value"`
+		} `json:"values"`
+	} `json:"state"`
+	ResponseURL string `json:"response_url"`
+}
+
+// approvalGate receives Slack interactivity requests for one approval request
+// and reports the first valid decision.
+type approvalGate struct {
+	requestID     string
+	signingSecret string
+	approvers     map[string]bool
+	now           func() time.Time
+	// printer localizes the texts posted back to Slack.
+	printer *message.Printer
+	// respond posts a follow-up to an interaction's response_url. It runs
+	// after the interaction was acknowledged.
+	respond func(responseURL string, msg map[string]any)
+
+	once      sync.Once
+	decisions chan approvalDecision
+	// pending tracks follow-ups still being posted.
+	pending sync.WaitGroup
+}
+
+// newApprovalGate creates a gate for a single approval request.
+func newApprovalGate(requestID, signingSecret string, approvers []string) *approvalGate {
+	allowed := make(map[string]bool, len(approvers))
+	for _, a := range approvers {
+		allowed[strings.TrimPrefix(a, "@")] = true
+	}
+	return &approvalGate{
+		requestID:     requestID,
+		signingSecret: signingSecret,
+		approvers:     allowed,
+		now:           time.Now,
+		printer:       newPrinter(""),
+		respond:       func(string, map[string]any) {},
+		decisions:     make(chan approvalDecision, 1),
+	}
+}
+
+// ServeHTTP handles a Slack interactivity request.
+func (g *approvalGate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInteractionSize))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	if err := verifySlackSignature(g.signingSecret, r.Header, body, g.now()); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	var payload interactionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects a 200 within three seconds; anything else is retried.
+	w.WriteHeader(http.StatusOK)
+
+	decision, ok := g.decide(payload)
+	if !ok {
+		return
+	}
+
+	g.once.Do(func() {
+		// The follow-up is pending before the decision wakes up wait's caller.
+		g.respondLater(payload.ResponseURL, map[string]any{
+			"replace_original": true,
+			"text":             decisionText(g.printer, decision, g.now()),
+		})
+		g.decisions <- decision
+	})
+}
+
+// respondLater posts a follow-up to responseURL in the background, so the
+// interaction is acknowledged within Slack's three seconds however long the
+// follow-up takes.
+func (g *approvalGate) respondLater(responseURL string, msg map[string]any) {
+	if responseURL == "" {
+		return
+	}
+	g.pending.Add(1)
+	go func() {
+		defer g.pending.Done()
+		g.respond(responseURL, msg)
+	}()
+}
+
+// wait blocks until every follow-up has been posted.
+func (g *approvalGate) wait() {
+	g.pending.Wait()
+}
+
+// decide extracts a decision from a payload, ignoring unrelated or unauthorized actions.
+func (g *approvalGate) decide(payload interactionPayload) (approvalDecision, bool) {
+	if payload.Type != "block_actions" {
+		return approvalDecision{}, false
+	}
+
+	for _, action := range payload.Actions {
+		if action.BlockID != approvalBlockPrefix+g.requestID {
+			continue
+		}
+		if action.ActionID != "approve" && action.ActionID != "reject" {
+			continue
+		}
+
+		if len(g.approvers) > 0 && !g.approvers[payload.User.ID] && !g.approvers[payload.User.Username] {
+			g.respondLater(payload.ResponseURL, map[string]any{
+				"response_type":    "ephemeral",
+				"replace_original": false,
+				"text":             translate(g.printer, "You are not allowed to approve this release."),
+			})
+			return approvalDecision{}, false
+		}
+
+		name := payload.User.Username
+		if name == "" {
+			name = payload.User.Name
+		}
+
+		reason := ""
+		if input, ok := payload.State.Values[reasonBlockPrefix+g.requestID]; ok {
+			reason = strings.TrimSpace(input["reason"].Value)
+		}
+
+		return approvalDecision{
+			Approved: action.ActionID == "approve",
+			UserID:   payload.User.ID,
+			UserName: name,
+			Reason:   reason,
+		}, true
+	}
+	return approvalDecision{}, false
+}
+
+// verifySlackSignature checks the X-Slack-Signature of a request body.
+// See https://api.slack.com/authentication/verifying-requests-from-slack.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	signature := header.Get("X-Slack-Signature")
+	if timestamp == "" || signature == "" {
+		return errors.New("missing Slack signature headers")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid Slack request timestamp")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return errors.New("stale Slack request timestamp")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(computeSlackSignature(secret, timestamp, body))) {
+		return errors.New("invalid Slack signature")
+	}
+	return nil
+}
+
+// computeSlackSignature returns the v0 signature Slack sends for a request body.
+func computeSlackSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "v0:%s:", timestamp)
+	_, _ = mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// decisionText describes a decision for the updated approval message.
+func decisionText(pr *message.Printer, d approvalDecision, at time.Time) string {
+	when := formatTimestamp(pr, at.UTC())
+	if d.Approved {
+		return ":white_check_mark: " + translate(pr, "Release approved by <@%s> on %s", d.UserID, when)
+	}
+	if d.Reason != "" {
+		return ":no_entry: " + translate(pr, "Release rejected by <@%s> on %s: %s", d.UserID, when, mrkdwnEscaper.Replace(d.Reason))
+	}
+	return ":no_entry: " + translate(pr, "Release rejected by <@%s> on %s", d.UserID, when)
+}
+
+// newRequestID returns a random identifier that ties buttons to one approval request.
+func newRequestID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate approval request ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// buildApprovalMessage builds the Block Kit approval request.
+func buildApprovalMessage(cfg *Config, requestID string, releaseCtx plugin.ReleaseContext) SlackMessage {
+	pr := cfg.printer()
+	data := newTemplateData(pr, releaseCtx)
+
+	summary := translate(pr, "Release *%s* is ready to publish.", data.Version)
+	if data.Repository != "" {
+		summary = translate(pr, "*%s* release *%s* is ready to publish.", data.Repository, data.Version)
+	}
+	if data.ReleaseType != "" {
+		summary += "\n" + translate(pr, "Release type: %s", releaseTypeName(pr, data.ReleaseType))
+	}
+	if data.Branch != "" {
+		summary += "\n" + translate(pr, "Branch: `%s`", data.Branch)
+	}
+	if data.Summary != "" {
+		summary += "\n" + data.Summary
+	}
+	if approvers := buildSlackMentions(cfg.Approvers); approvers != "" {
+		summary += "\n" + translate(pr, "Approvers: %s", approvers)
+	}
+
+	return SlackMessage{
+		Channel:   cfg.Channel,
+		Username:  cfg.Username,
+		IconEmoji: cfg.IconEmoji,
+		IconURL:   cfg.IconURL,
+		Text:      translate(pr, "Approval required for release %s", data.Version),
+		Blocks: []any{
+			Block{Type: "header", Text: plainText(translate(pr, "Approval required: %s", releaseCtx.Version))},
+			Block{Type: "section", Text: mrkdwnText(summary)},
+			Block{
+				Type:     "input",
+				BlockID:  reasonBlockPrefix + requestID,
+				Optional: true,
+				Label:    plainText(translate(pr, "Reason")),
+				Element: PlainTextInputElement{
+					Type:        "plain_text_input",
+					ActionID:    "reason",
+					Placeholder: plainText(translate(pr, "Why are you rejecting this release?")),
+				},
+			},
+			Block{
+				Type:    "actions",
+				BlockID: approvalBlockPrefix + requestID,
+				Elements: []any{
+					ButtonElement{Type: "button", Text: plainText(translate(pr, "Approve")), ActionID: "approve", Value: requestID, Style: "primary"},
+					ButtonElement{Type: "button", Text: plainText(translate(pr, "Reject")), ActionID: "reject", Value: requestID, Style: "danger"},
+				},
+			},
+		},
+	}
+}
+
+// requestApproval posts an approval request and blocks until a decision,
+// the configured timeout or context cancellation.
+func (p *SlackPlugin) requestApproval(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+	if dryRun {
+		outputs := map[string]any{
+			"channel":     cfg.Channel,
+			"listen_addr": cfg.ApprovalListenAddr,
+		}
+		// Approval requests only go to the top-level destination.
+		msg, fixes, err := cfg.preparePayload(buildApprovalMessage(cfg, "preview", releaseCtx))
+		if err == nil {
+			preview := newMessagePreview(cfg, msg)
+			preview.Fixes = fixes
+			err = cfg.previewOutputs(outputs, "approval", []messagePreview{preview})
+		}
+		if err != nil {
+			return &plugin.ExecuteResponse{Success: false, Error: err.Error()}, nil
+		}
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: "Would request Slack approval",
+			Outputs: outputs,
+		}, nil
+	}
+
+	ln, err := net.Listen("tcp", cfg.ApprovalListenAddr)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to start approval listener: %v", err),
+		}, nil
+	}
+	return p.awaitApproval(ctx, cfg, releaseCtx, ln), nil
+}
+
+// awaitApproval serves interactivity requests on ln until the release is decided.
+func (p *SlackPlugin) awaitApproval(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, ln net.Listener) *plugin.ExecuteResponse {
+	requestID, err := newRequestID()
+	if err != nil {
+		_ = ln.Close()
+		return &plugin.ExecuteResponse{Success: false, Error: err.Error()}
+	}
+
+	gate := newApprovalGate(requestID, cfg.ApprovalSigningSecret, cfg.Approvers)
+	gate.printer = cfg.printer()
+	gate.now = p.now
+	// Follow-ups outlive a cancelled hook, but not their own timeout.
+	gate.respond = func(responseURL string, msg map[string]any) {
+		respondCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), approvalResponseTimeout)
+		defer cancel()
+		p.respondToInteraction(respondCtx, responseURL, msg)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(approvalPath, gate)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+		// Let the follow-ups, such as the decision's update of the request,
+		// be posted before returning.
+		gate.wait()
+	}()
+
+	msg, err := buildPayload(ctx, cfg, func(target *Config) (SlackMessage, error) {
+		return buildApprovalMessage(target, requestID, releaseCtx), nil
+	})
+	var posted *postedMessage
+	if err == nil {
+		posted, err = p.deliver(ctx, cfg, msg)
+	}
+	p.auditDelivery(ctx, cfg, msg, false, posted, err)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to send Slack approval request: %v", err),
+		}
+	}
+
+	timer := time.NewTimer(cfg.ApprovalTimeout)
+	defer timer.Stop()
+
+	select {
+	case d := <-gate.decisions:
+		if !d.Approved {
+			msg := fmt.Sprintf("release rejected by %s", d.UserName)
+			if d.Reason != "" {
+				msg += ": " + d.Reason
+			}
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Error:   msg,
+				Outputs: map[string]any{"rejected_by": d.UserID, "reason": d.Reason},
+			}
+		}
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: fmt.Sprintf("Release approved by %s", d.UserName),
+			Outputs: map[string]any{"approved_by": d.UserID},
+		}
+
+	case <-timer.C:
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("no approval decision within %s", cfg.ApprovalTimeout),
+		}
+
+	case <-ctx.Done():
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("approval cancelled: %v", ctx.Err()),
+		}
+	}
+}
+
+// withApproval merges a passed approval into the response of the publishing
+// announcement, so both outputs and previews are reported.
+func withApproval(approval, resp *plugin.ExecuteResponse) *plugin.ExecuteResponse {
+	if resp.Outputs == nil {
+		resp.Outputs = map[string]any{}
+	}
+	for k, v := range approval.Outputs {
+		existing, ok := resp.Outputs[k]
+		switch {
+		case !ok:
+			resp.Outputs[k] = v
+		case k == "previews":
+			approvalPreviews, _ := v.([]any)
+			previews, _ := existing.([]any)
+			resp.Outputs[k] = append(approvalPreviews, previews...)
+		}
+	}
+	if resp.Success {
+		resp.Message = approval.Message + "; " + resp.Message
+	}
+	return resp
+}
+
+// respondToInteraction posts a follow-up to a Slack response_url.
+// Only Slack-hosted HTTPS URLs are accepted, since the URL comes from the request.
+func (p *SlackPlugin) respondToInteraction(ctx context.Context, responseURL string, msg map[string]any) {
+	parsed, err := url.Parse(responseURL)
+	if err != nil || parsed.Scheme != "https" || !allowedSlackHosts[parsed.Host] {
+		return
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	_, _, _, _ = p.postWebhook(ctx, responseURL, payload)
+}
@@ -0,0 +1,418 @@
+// Package main provides tests for the Slack approval gate.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signedInteractionRequest builds a signed Slack interactivity request for payload.
+func signedInteractionRequest(t *testing.T, target string, payload map[string]any, ts time.Time) *http.Request {
+	t.Helper()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	body := []byte(url.Values{"payload": {string(data)}}.Encode())
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", computeSlackSignature(testSigningSecret, timestamp, body))
+	return req
+}
+
+// blockActionsPayload builds a block_actions payload for an approval request.
+func blockActionsPayload(requestID, actionID, userID, reason string) map[string]any {
+	return map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]any{"id": userID, "username": "user-" + userID},
+		"response_url": "https://hooks.slack.com/actions/T0/1/xyz",
+		"actions": []any{
+			map[string]any{"action_id": actionID, "block_id": approvalBlockPrefix + requestID, "value": requestID},
+		},
+		"state": map[string]any{
+			"values": map[string]any{
+				reasonBlockPrefix + requestID: map[string]any{
+					"reason": map[string]any{"type": "plain_text_input", "value": reason},
+				},
+			},
+		},
+	}
+}
+
+// TestVerifySlackSignature tests request signature verification.
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("payload=%7B%7D")
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      []byte
+		wantErr   string
+	}{
+		{name: "valid", timestamp: ts, signature: computeSlackSignature(testSigningSecret, ts, body), body: body},
+		{name: "missing headers", body: body, wantErr: "missing Slack signature headers"},
+		{name: "wrong secret", timestamp: ts, signature: computeSlackSignature("other", ts, body), body: body, wantErr: "invalid Slack signature"},
+		{name: "tampered body", timestamp: ts, signature: computeSlackSignature(testSigningSecret, ts, body), body: []byte("payload=evil"), wantErr: "invalid Slack signature"},
+		{
+			name:      "stale timestamp",
+			timestamp: strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10),
+			signature: computeSlackSignature(testSigningSecret, strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10), body),
+			body:      body,
+			wantErr:   "stale Slack request timestamp",
+		},
+		{name: "malformed timestamp", timestamp: "yesterday", signature: "v0=00", body: body, wantErr: "invalid Slack request timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.timestamp != "" {
+				header.Set("X-Slack-Request-Timestamp", tt.timestamp)
+			}
+			if tt.signature != "" {
+				header.Set("X-Slack-Signature", tt.signature)
+			}
+
+			err := verifySlackSignature(testSigningSecret, header, tt.body, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestApprovalGate tests decision handling for interactivity requests.
+func TestApprovalGate(t *testing.T) {
+	const requestID = "abc123"
+
+	tests := []struct {
+		name         string
+		approvers    []string
+		payload      map[string]any
+		tamper       bool
+		wantStatus   int
+		wantDecision *approvalDecision
+	}{
+		{
+			name:         "approve",
+			payload:      blockActionsPayload(requestID, "approve", "U1", ""),
+			wantStatus:   http.StatusOK,
+			wantDecision: &approvalDecision{Approved: true, UserID: "U1", UserName: "user-U1"},
+		},
+		{
+			name:         "reject with reason",
+			payload:      blockActionsPayload(requestID, "reject", "U2", " tests are red "),
+			wantStatus:   http.StatusOK,
+			wantDecision: &approvalDecision{Approved: false, UserID: "U2", UserName: "user-U2", Reason: "tests are red"},
+		},
+		{
+			name:       "unauthorized approver ignored",
+			approvers:  []string{"U9"},
+			payload:    blockActionsPayload(requestID, "approve", "U1", ""),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "approver by username",
+			approvers:    []string{"@user-U1"},
+			payload:      blockActionsPayload(requestID, "approve", "U1", ""),
+			wantStatus:   http.StatusOK,
+			wantDecision: &approvalDecision{Approved: true, UserID: "U1", UserName: "user-U1"},
+		},
+		{
+			name:       "stale request buttons ignored",
+			payload:    blockActionsPayload("other", "approve", "U1", ""),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bad signature rejected",
+			payload:    blockActionsPayload(requestID, "approve", "U1", ""),
+			tamper:     true,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := newApprovalGate(requestID, testSigningSecret, tt.approvers)
+			var (
+				mu        sync.Mutex
+				responses []map[string]any
+			)
+			gate.respond = func(_ string, msg map[string]any) {
+				mu.Lock()
+				defer mu.Unlock()
+				responses = append(responses, msg)
+			}
+
+			req := signedInteractionRequest(t, "http://gate"+approvalPath, tt.payload, time.Now())
+			if tt.tamper {
+				req.Header.Set("X-Slack-Signature", "v0=deadbeef")
+			}
+			rec := httptest.NewRecorder()
+			gate.ServeHTTP(rec, req)
+			gate.wait()
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			select {
+			case d := <-gate.decisions:
+				if tt.wantDecision == nil {
+					t.Fatalf("expected no decision, got %+v", d)
+				}
+				if d != *tt.wantDecision {
+					t.Errorf("expected %+v, got %+v", *tt.wantDecision, d)
+				}
+				if len(responses) != 1 || responses[0]["replace_original"] != true {
+					t.Errorf("expected original message to be replaced, got %v", responses)
+				}
+			default:
+				if tt.wantDecision != nil {
+					t.Fatal("expected a decision, got none")
+				}
+			}
+		})
+	}
+
+	t.Run("first decision wins", func(t *testing.T) {
+		gate := newApprovalGate(requestID, testSigningSecret, nil)
+		for _, action := range []string{"approve", "reject"} {
+			req := signedInteractionRequest(t, "http://gate"+approvalPath, blockActionsPayload(requestID, action, "U1", ""), time.Now())
+			gate.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		if d := <-gate.decisions; !d.Approved {
+			t.Errorf("expected first (approve) decision, got %+v", d)
+		}
+	})
+
+	t.Run("acknowledged before the follow-up", func(t *testing.T) {
+		gate := newApprovalGate(requestID, testSigningSecret, nil)
+		release := make(chan struct{})
+		gate.respond = func(string, map[string]any) { <-release }
+
+		payload := blockActionsPayload(requestID, "approve", "U1", "")
+		payload["response_url"] = "https://hooks.slack.com/actions/T1/1/abc"
+		done := make(chan struct{})
+		go func() {
+			gate.ServeHTTP(httptest.NewRecorder(), signedInteractionRequest(t, "http://gate"+approvalPath, payload, time.Now()))
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("expected the interaction to be acknowledged while the follow-up is pending")
+		}
+		close(release)
+		gate.wait()
+	})
+}
+
+// TestApprovalFlow tests the full request, callback and decision flow against a fake Slack.
+func TestApprovalFlow(t *testing.T) {
+	p := &SlackPlugin{}
+	releaseCtx := plugin.ReleaseContext{Version: "3.0.0", TagName: "v3.0.0", ReleaseType: "major", Branch: "main"}
+
+	// fakeSlack plays the role of Slack: it receives the approval request and,
+	// when action is set, calls back into the listener as a user clicking a button.
+	fakeSlack := func(t *testing.T, listener net.Listener, action, reason string) *httptest.Server {
+		return useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var msg struct {
+				Text   string  `json:"text"`
+				Blocks []Block `json:"blocks"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+				t.Errorf("failed to decode approval request: %v", err)
+			}
+			w.WriteHeader(http.StatusOK)
+
+			if msg.Text != "Approval required for release 3.0.0" {
+				t.Errorf("unexpected fallback text %q", msg.Text)
+			}
+			if action == "" {
+				return
+			}
+
+			var requestID string
+			for _, b := range msg.Blocks {
+				if strings.HasPrefix(b.BlockID, approvalBlockPrefix) {
+					requestID = strings.TrimPrefix(b.BlockID, approvalBlockPrefix)
+				}
+			}
+			if requestID == "" {
+				t.Error("expected approval request to contain an actions block")
+				return
+			}
+
+			// A non-Slack response_url is never called back, keeping the test offline.
+			payload := blockActionsPayload(requestID, action, "U42", reason)
+			payload["response_url"] = "http://" + r.Host + "/actions"
+
+			go func() {
+				req := signedInteractionRequest(t, "http://"+listener.Addr().String()+approvalPath, payload, time.Now())
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Errorf("callback failed: %v", err)
+					return
+				}
+				_ = resp.Body.Close()
+			}()
+		}))
+	}
+
+	newListener := func(t *testing.T) net.Listener {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen: %v", err)
+		}
+		return ln
+	}
+
+	t.Run("approved", func(t *testing.T) {
+		ln := newListener(t)
+		server := fakeSlack(t, ln, "approve", "")
+		cfg := p.parseConfig(map[string]any{"webhook": server.URL, "approval_required": true, "approval_signing_secret": testSigningSecret})
+
+		resp := p.awaitApproval(context.Background(), cfg, releaseCtx, ln)
+		if !resp.Success {
+			t.Fatalf("expected approval, got %+v", resp)
+		}
+		if resp.Outputs["approved_by"] != "U42" {
+			t.Errorf("expected approved_by U42, got %v", resp.Outputs["approved_by"])
+		}
+	})
+
+	t.Run("rejected with reason", func(t *testing.T) {
+		ln := newListener(t)
+		server := fakeSlack(t, ln, "reject", "changelog is wrong")
+		cfg := p.parseConfig(map[string]any{"webhook": server.URL, "approval_required": true, "approval_signing_secret": testSigningSecret})
+
+		resp := p.awaitApproval(context.Background(), cfg, releaseCtx, ln)
+		if resp.Success {
+			t.Fatalf("expected rejection, got %+v", resp)
+		}
+		if resp.Error != "release rejected by user-U42: changelog is wrong" {
+			t.Errorf("unexpected error %q", resp.Error)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ln := newListener(t)
+		server := fakeSlack(t, ln, "", "")
+		cfg := p.parseConfig(map[string]any{"webhook": server.URL, "approval_signing_secret": testSigningSecret})
+		cfg.ApprovalTimeout = 50 * time.Millisecond
+
+		resp := p.awaitApproval(context.Background(), cfg, releaseCtx, ln)
+		if resp.Success || !strings.Contains(resp.Error, "no approval decision within") {
+			t.Errorf("expected timeout, got %+v", resp)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ln := newListener(t)
+		server := fakeSlack(t, ln, "", "")
+		cfg := p.parseConfig(map[string]any{"webhook": server.URL, "approval_signing_secret": testSigningSecret})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		resp := p.awaitApproval(ctx, cfg, releaseCtx, ln)
+		if resp.Success || !strings.Contains(resp.Error, "approval cancelled") {
+			t.Errorf("expected cancellation, got %+v", resp)
+		}
+	})
+
+	t.Run("dry run does not block", func(t *testing.T) {
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPrePublish,
+			Config: map[string]any{
+				"webhook":                 "https://hooks.slack.com/services/T00/B00/XXX",
+				"approval_required":       true,
+				"approval_signing_secret": testSigningSecret,
+			},
+			Context: releaseCtx,
+			DryRun:  true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Success || resp.Message != "Would request Slack approval" {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	})
+
+	t.Run("dry run with publishing notification", func(t *testing.T) {
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPrePublish,
+			Config: map[string]any{
+				"webhook":                 "https://hooks.slack.com/services/T00/B00/XXX",
+				"approval_required":       true,
+				"approval_signing_secret": testSigningSecret,
+				"notify_on_publishing":    true,
+			},
+			Context: releaseCtx,
+			DryRun:  true,
+		})
+		if err != nil || !resp.Success {
+			t.Fatalf("expected success, got %v / %+v", err, resp)
+		}
+		if !strings.HasPrefix(resp.Message, "Would request Slack approval; ") {
+			t.Errorf("expected both messages, got %q", resp.Message)
+		}
+		previews, _ := resp.Outputs["previews"].([]any)
+		if len(previews) != 2 {
+			t.Fatalf("expected the approval and publishing previews, got %v", resp.Outputs["previews"])
+		}
+		if text := previews[0].(map[string]any)["text"]; !strings.Contains(fmt.Sprint(text), "Approval required") {
+			t.Errorf("expected the approval preview first, got %v", previews[0])
+		}
+		if resp.Outputs["listen_addr"] == nil {
+			t.Errorf("expected the approval outputs, got %v", resp.Outputs)
+		}
+	})
+
+	t.Run("signing secret is required", func(t *testing.T) {
+		t.Setenv("SLACK_SIGNING_SECRET", "")
+		resp, err := p.Validate(context.Background(), map[string]any{
+			"webhook":           "https://hooks.slack.com/services/T00000000/B00000000/TESTTOKEN",
+			"approval_required": true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if e := findError(resp, "approval_signing_secret"); e == nil || e.Code != "required" {
+			t.Errorf("expected required error, got %v", resp.Errors)
+		}
+	})
+}
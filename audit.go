@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// auditFileName is the name of the audit log inside the state directory.
+const auditFileName = "audit.jsonl"
+
+// auditTailSize is how much of the end of the audit log is read to find the
+// last entry of the HMAC chain.
+const auditTailSize = 64 << 10
+
+// Delivery outcomes recorded in the audit log.
+const (
+	auditSent      = "sent"
+	auditScheduled = "scheduled"
+	auditFailed    = "failed"
+)
+
+// mentionPattern matches the mentions Slack notifies: broadcasts, users and user groups.
+var mentionPattern = regexp.MustCompile(`<(?:!here|!channel|!everyone|!subteam\^[A-Z0-9]+(?:\|[^>]*)?|@[UW][A-Z0-9]+)>`)
+
+// auditEntry is one line of the audit log: one message delivery to one
+// destination.
+type auditEntry struct {
+	Time          time.Time `json:"time"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Hook          string    `json:"hook,omitempty"`
+	Version       string    `json:"version,omitempty"`
+	Tag           string    `json:"tag,omitempty"`
+	Commit        string    `json:"commit,omitempty"`
+	// Destination is the configured channel, or "webhook" for the webhook's default channel.
+	Destination string `json:"destination"`
+	// ChannelID, TS and Permalink identify the posted message in bot-token mode.
+	ChannelID string `json:"channel_id,omitempty"`
+	TS        string `json:"ts,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+	// Mentions are the users, groups and broadcasts notified, as Slack tokens.
+	Mentions []string `json:"mentions,omitempty"`
+	// PayloadSHA256 is the hash of the message JSON.
+	PayloadSHA256 string `json:"payload_sha256,omitempty"`
+	Outcome       string `json:"outcome"`
+	Error         string `json:"error,omitempty"`
+	// PrevHMAC and HMAC chain the entries when an HMAC key is configured.
+	// HMAC must stay the last field: it signs the line up to its own field.
+	PrevHMAC string `json:"prev_hmac,omitempty"`
+	HMAC     string `json:"hmac,omitempty"`
+}
+
+// auditScopeKey keys the auditScope of an execution in its context.
+type auditScopeKey struct{}
+
+// auditScope is what the audit log records about the execution a delivery belongs to.
+type auditScope struct {
+	hook          plugin.Hook
+	release       plugin.ReleaseContext
+	correlationID string
+}
+
+// withAuditScope returns ctx carrying the execution's audit scope.
+func withAuditScope(ctx context.Context, s auditScope) context.Context {
+	return context.WithValue(ctx, auditScopeKey{}, s)
+}
+
+// auditPath returns the audit log file.
+func (c *Config) auditPath() string {
+	if c.AuditFile != "" {
+		return c.AuditFile
+	}
+	return filepath.Join(c.StateDir, auditFileName)
+}
+
+// auditDelivery records the delivery of msg to target when the audit log is
+// enabled. posted and err are the result of the delivery. Like the history,
+// the audit log never fails a release: write errors are only logged.
+func (p *SlackPlugin) auditDelivery(ctx context.Context, target *Config, msg SlackMessage, scheduled bool, posted *postedMessage, err error) {
+	if !target.Audit {
+		return
+	}
+	scope, _ := ctx.Value(auditScopeKey{}).(auditScope)
+	e := auditEntry{
+		Time:          p.now().UTC(),
+		CorrelationID: scope.correlationID,
+		Hook:          string(scope.hook),
+		Version:       scope.release.Version,
+		Tag:           scope.release.TagName,
+		Commit:        scope.release.CommitSHA,
+		Destination:   target.destinationName(),
+		Mentions:      payloadMentions(msg),
+		Outcome:       auditSent,
+	}
+	if payload, err := json.Marshal(msg); err == nil && (len(msg.Blocks) > 0 || len(msg.Attachments) > 0 || msg.Text != "") {
+		sum := sha256.Sum256(payload)
+		e.PayloadSHA256 = hex.EncodeToString(sum[:])
+	}
+	if posted != nil {
+		e.ChannelID, e.TS, e.Permalink = posted.Channel, posted.TS, posted.Permalink
+	}
+	switch {
+	case err != nil:
+		e.Outcome = auditFailed
+		e.Error = newRedactor(target.secrets()...).String(err.Error())
+	case scheduled && target.BotToken != "":
+		e.Outcome = auditScheduled
+	}
+
+	if err := appendAudit(target.auditPath(), e, target.AuditHMACKey, int64(target.AuditMaxSizeMB)<<20, target.AuditMaxFiles); err != nil {
+		logger(ctx).Error("failed to write audit log", "error", err)
+	}
+}
+
+// payloadMentions returns the distinct mentions in msg, in order.
+func payloadMentions(msg SlackMessage) []string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(msg); err != nil {
+		return nil
+	}
+	var mentions []string
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllString(buf.String(), -1) {
+		if !seen[m] {
+			seen[m] = true
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
+
+// appendAudit appends e to the audit log at path, chaining it to the last
+// entry when key is set. The log is rotated to path.1, path.2, … before it
+// would grow beyond maxSize bytes; maxFiles rotated files are kept.
+func appendAudit(path string, e auditEntry, key string, maxSize int64, maxFiles int) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	if key != "" {
+		prev, err := lastAuditHMAC(path)
+		if err != nil {
+			return err
+		}
+		e.PrevHMAC = prev
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if key != "" {
+		e.HMAC = auditHMAC(key, line)
+		if line, err = json.Marshal(e); err != nil {
+			return fmt.Errorf("failed to encode audit entry: %w", err)
+		}
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 && maxSize > 0 && info.Size()+int64(len(line))+1 > maxSize {
+		if err := rotateAudit(path, maxFiles); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// auditHMAC signs an entry encoded without its hmac field.
+func auditHMAC(key string, unsigned []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(unsigned)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// lastAuditHMAC returns the HMAC of the last entry in the audit log, or ""
+// if the log is missing or empty.
+func lastAuditHMAC(path string) (string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+	offset := max(info.Size()-auditTailSize, 0)
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(tail), []byte("\n"))
+	var last auditEntry
+	if err := json.Unmarshal(lines[len(lines)-1], &last); err != nil && len(tail) > 0 {
+		return "", fmt.Errorf("failed to read the last audit entry: %w", err)
+	}
+	return last.HMAC, nil
+}
+
+// rotateAudit shifts path.1 … path.(maxFiles-1) up by one, dropping the
+// oldest, and moves path to path.1.
+func rotateAudit(path string, maxFiles int) error {
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	for i := maxFiles - 1; i >= 1; i-- {
+		err := os.Rename(path+"."+strconv.Itoa(i), path+"."+strconv.Itoa(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return nil
+}
+
+// auditFiles returns the existing files of the audit log at path, oldest first.
+func auditFiles(path string, maxFiles int) []string {
+	var files []string
+	for i := max(maxFiles, 1); i >= 1; i-- {
+		if _, err := os.Stat(path + "." + strconv.Itoa(i)); err == nil {
+			files = append(files, path+"."+strconv.Itoa(i))
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
+
+// verifyAudit checks the HMAC chain across files, oldest first, and returns
+// the number of entries verified. The first entry may link to an entry that
+// has been rotated away; every later entry must link to the one before it.
+func verifyAudit(files []string, key string) (int, error) {
+	var (
+		n    int
+		prev string
+	)
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return n, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+		for line := 1; scanner.Scan(); line++ {
+			raw := scanner.Bytes()
+			if len(bytes.TrimSpace(raw)) == 0 {
+				continue
+			}
+			if err := verifyAuditLine(raw, key, prev, n == 0); err != nil {
+				_ = f.Close()
+				return n, fmt.Errorf("%s line %d: %w", filepath.Base(path), line, err)
+			}
+			var e auditEntry
+			_ = json.Unmarshal(raw, &e)
+			prev = e.HMAC
+			n++
+		}
+		err = scanner.Err()
+		_ = f.Close()
+		if err != nil {
+			return n, fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// verifyAuditLine checks the signature of one entry and its link to prev.
+func verifyAuditLine(raw []byte, key, prev string, first bool) error {
+	var e auditEntry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return fmt.Errorf("invalid entry: %w", err)
+	}
+	if e.HMAC == "" {
+		return errors.New("entry is not signed")
+	}
+	if !first && e.PrevHMAC != prev {
+		return errors.New("chain is broken: an entry was removed or reordered")
+	}
+	suffix := []byte(`,"hmac":"` + e.HMAC + `"}`)
+	if !bytes.HasSuffix(raw, suffix) {
+		return errors.New("hmac is not the last field")
+	}
+	unsigned := append(bytes.Clone(bytes.TrimSuffix(raw, suffix)), '}')
+	if !hmac.Equal([]byte(auditHMAC(key, unsigned)), []byte(e.HMAC)) {
+		return errors.New("hmac mismatch: the entry was modified")
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/message"
+)
+
+// Block is a Slack Block Kit layout block.
+// Only the fields used by this plugin are modelled.
+type Block struct {
+	Type     string        `json:"type"`
+	BlockID  string        `json:"block_id,omitempty"`
+	Text     *TextObject   `json:"text,omitempty"`
+	Fields   []*TextObject `json:"fields,omitempty"`
+	Elements []any         `json:"elements,omitempty"`
+	Label    *TextObject   `json:"label,omitempty"`
+	Element  any           `json:"element,omitempty"`
+	Optional bool          `json:"optional,omitempty"`
+}
+
+// TextObject is a Block Kit text object.
+type TextObject struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// ButtonElement is a Block Kit button.
+type ButtonElement struct {
+	Type     string      `json:"type"`
+	Text     *TextObject `json:"text"`
+	ActionID string      `json:"action_id"`
+	Value    string      `json:"value,omitempty"`
+	Style    string      `json:"style,omitempty"`
+	URL      string      `json:"url,omitempty"`
+}
+
+// PlainTextInputElement is a Block Kit plain text input.
+type PlainTextInputElement struct {
+	Type        string      `json:"type"`
+	ActionID    string      `json:"action_id"`
+	Placeholder *TextObject `json:"placeholder,omitempty"`
+	Multiline   bool        `json:"multiline,omitempty"`
+}
+
+// plainText returns a plain_text text object.
+func plainText(text string) *TextObject {
+	return &TextObject{Type: "plain_text", Text: text, Emoji: true}
+}
+
+// mrkdwnText returns a mrkdwn text object.
+func mrkdwnText(text string) *TextObject {
+	return &TextObject{Type: "mrkdwn", Text: text}
+}
+
+// attachmentBlocks lays out an attachment's content as Block Kit blocks:
+// a header, the fields, the text and a footer with the localized time.
+func attachmentBlocks(pr *message.Printer, att Attachment) []any {
+	var short, long []*TextObject
+	for _, f := range att.Fields {
+		field := mrkdwnText(fmt.Sprintf("*%s*\n%s", f.Title, mrkdwnEscaper.Replace(f.Value)))
+		if f.Short {
+			short = append(short, field)
+		} else {
+			long = append(long, field)
+		}
+	}
+
+	// Short fields sit side by side, as in attachments.
+	blocks := []any{Block{Type: "header", Text: plainText(att.Title)}}
+	if len(short) > 0 {
+		blocks = append(blocks, Block{Type: "section", Fields: short})
+	}
+	for _, field := range long {
+		blocks = append(blocks, Block{Type: "section", Text: field})
+	}
+
+	if att.Text != "" {
+		blocks = append(blocks, Block{Type: "section", Text: mrkdwnText(att.Text)})
+	}
+
+	if len(att.Actions) > 0 {
+		buttons := make([]any, len(att.Actions))
+		for i, a := range att.Actions {
+			buttons[i] = ButtonElement{Type: "button", Text: plainText(a.Text), ActionID: a.Name, URL: a.URL}
+		}
+		blocks = append(blocks, Block{Type: "actions", Elements: buttons})
+	}
+
+	footer := att.Footer
+	if att.Ts != 0 {
+		at := time.Unix(att.Ts, 0)
+		footer += fmt.Sprintf(" | <!date^%d^{date_short_pretty} {time}|%s>", att.Ts, formatTimestamp(pr, at.UTC()))
+	}
+	if footer != "" {
+		blocks = append(blocks, Block{Type: "context", Elements: []any{mrkdwnText(strings.TrimPrefix(footer, " | "))}})
+	}
+	return blocks
+}
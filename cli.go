@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// Exit codes of the command line interface.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// pluginName is the name of the plugin's entry in a Relicta configuration.
+const pluginName = "slack"
+
+const cliUsage = `Usage: plugin-slack <command> [flags]
+
+Without a command the binary serves the Relicta plugin protocol.
+
+Commands:
+  preview       Render the notification of a hook without sending it
+  send          Send the notification of a hook (--dry-run to preview)
+  validate      Validate a configuration
+  test-webhook  Check that the configured destination is reachable
+  verify-audit  Check the HMAC chain of the audit log
+
+Run "plugin-slack <command> -h" for the flags of a command.
+`
+
+// runCLI runs a command line subcommand and returns the process exit code.
+// Commands go through Execute and Validate, exactly as a Relicta host would.
+func runCLI(ctx context.Context, p *SlackPlugin, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, cliUsage)
+		return exitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "preview":
+		err = cliSend(ctx, p, args[0], args[1:], true, stdout, stderr)
+	case "send":
+		err = cliSend(ctx, p, args[0], args[1:], false, stdout, stderr)
+	case "validate":
+		err = cliValidate(ctx, p, args[1:], false, stdout, stderr)
+	case "test-webhook":
+		err = cliValidate(ctx, p, args[1:], true, stdout, stderr)
+	case "verify-audit":
+		err = cliVerifyAudit(p, args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		_, _ = fmt.Fprint(stdout, cliUsage)
+		return exitOK
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], cliUsage)
+		return exitUsage
+	}
+
+	var failed errFailed
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &failed):
+		return exitFailure
+	case errors.Is(err, errUsage):
+		return exitUsage
+	default:
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitFailure
+	}
+}
+
+// errUsage reports invalid flags; the flag set has already printed them.
+var errUsage = errors.New("usage")
+
+// errFailed reports an unsuccessful execution or an invalid configuration
+// whose details have already been printed.
+type errFailed struct{}
+
+func (errFailed) Error() string { return "failed" }
+
+// cliFlags are the flags shared by the commands.
+type cliFlags struct {
+	set         *flag.FlagSet
+	configPath  string
+	contextPath string
+	hook        string
+	slackURL    string
+}
+
+// newCLIFlags declares the flags of a command.
+func newCLIFlags(name string, stderr io.Writer) *cliFlags {
+	f := &cliFlags{set: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.set.SetOutput(stderr)
+	f.set.StringVar(&f.configPath, "config", "", "plugin configuration (YAML or JSON); either the plugin's config or a Relicta configuration with a slack plugin")
+	f.set.StringVar(&f.slackURL, "slack-url", "", "send Slack requests to this base URL instead, e.g. a slacktest server")
+	return f
+}
+
+// parse parses args, mapping flag errors to errUsage, and points p at
+// --slack-url when given.
+func (f *cliFlags) parse(p *SlackPlugin, args []string) error {
+	if err := f.set.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if f.set.NArg() > 0 {
+		_, _ = fmt.Fprintf(f.set.Output(), "unexpected arguments: %s\n", strings.Join(f.set.Args(), " "))
+		return errUsage
+	}
+	if f.slackURL != "" {
+		WithBaseURL(f.slackURL)(p)
+	}
+	return nil
+}
+
+// cliSend runs a hook through Execute and prints the response as JSON.
+// preview always runs dry; send does with --dry-run.
+func cliSend(ctx context.Context, p *SlackPlugin, name string, args []string, preview bool, stdout, stderr io.Writer) error {
+	f := newCLIFlags(name, stderr)
+	f.set.StringVar(&f.contextPath, "context", "", "release context as JSON, in the format Relicta passes to plugins")
+	f.set.StringVar(&f.hook, "hook", string(plugin.HookPostPublish), "hook to run, e.g. pre-init, post-plan, pre-publish, on-success, on-error")
+	dryRun := preview
+	if !preview {
+		f.set.BoolVar(&dryRun, "dry-run", false, "render the notification without sending it")
+	}
+	if err := f.parse(p, args); err != nil {
+		return err
+	}
+
+	config, err := loadCLIConfig(f.configPath)
+	if err != nil {
+		return err
+	}
+	var releaseCtx plugin.ReleaseContext
+	if f.contextPath != "" {
+		if releaseCtx, err = loadReleaseContext(f.contextPath); err != nil {
+			return err
+		}
+	}
+
+	resp, err := p.Execute(ctx, plugin.ExecuteRequest{
+		Hook:    plugin.Hook(f.hook),
+		Config:  config,
+		Context: releaseCtx,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	if err := printJSON(stdout, resp); err != nil {
+		return err
+	}
+	if !resp.Success {
+		return errFailed{}
+	}
+	return nil
+}
+
+// cliValidate runs Validate and prints the findings, one per line.
+// test-webhook forces preflight, optionally posting to a sandbox channel.
+func cliValidate(ctx context.Context, p *SlackPlugin, args []string, probe bool, stdout, stderr io.Writer) error {
+	name := "validate"
+	if probe {
+		name = "test-webhook"
+	}
+	f := newCLIFlags(name, stderr)
+	var channel string
+	if probe {
+		f.set.StringVar(&channel, "channel", "", "post a visible test message to this sandbox channel instead of probing the webhook")
+	}
+	if err := f.parse(p, args); err != nil {
+		return err
+	}
+
+	config, err := loadCLIConfig(f.configPath)
+	if err != nil {
+		return err
+	}
+	if probe {
+		config["preflight"] = true
+		if channel != "" {
+			config["preflight_channel"] = channel
+		}
+	}
+
+	resp, err := p.Validate(ctx, config)
+	if err != nil {
+		return err
+	}
+	for _, e := range resp.Errors {
+		_, _ = fmt.Fprintf(stdout, "%s: %s [%s]\n", e.Field, e.Message, e.Code)
+	}
+	if !resp.Valid {
+		return errFailed{}
+	}
+	if probe {
+		_, _ = fmt.Fprintln(stdout, "destination is reachable")
+	} else {
+		_, _ = fmt.Fprintln(stdout, "configuration is valid")
+	}
+	return nil
+}
+
+// cliVerifyAudit checks the HMAC chain of the configured audit log,
+// including its rotated files.
+func cliVerifyAudit(p *SlackPlugin, args []string, stdout, stderr io.Writer) error {
+	f := newCLIFlags("verify-audit", stderr)
+	if err := f.parse(p, args); err != nil {
+		return err
+	}
+	config, err := loadCLIConfig(f.configPath)
+	if err != nil {
+		return err
+	}
+	cfg := p.parseConfig(config)
+	if cfg.AuditHMACKey == "" {
+		return errors.New("audit_hmac_key or SLACK_AUDIT_HMAC_KEY is required to verify the audit log")
+	}
+	files := auditFiles(cfg.auditPath(), cfg.AuditMaxFiles)
+	if len(files) == 0 {
+		return fmt.Errorf("no audit log at %s", cfg.auditPath())
+	}
+
+	n, err := verifyAudit(files, cfg.AuditHMACKey)
+	if err != nil {
+		_, _ = fmt.Fprintf(stdout, "audit log verification failed after %d entries: %v\n", n, err)
+		return errFailed{}
+	}
+	_, _ = fmt.Fprintf(stdout, "%d audit entries verified\n", n)
+	return nil
+}
+
+// loadCLIConfig reads the plugin configuration from path. A Relicta
+// configuration is recognized by its plugins list, and the config of the
+// slack plugin is used. Without a path the configuration is empty, so only
+// environment variables apply.
+func loadCLIConfig(path string) (map[string]any, error) {
+	if path == "" {
+		return map[string]any{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	// Round-trip through JSON so values have the types the host passes,
+	// e.g. timestamps as strings and numbers as float64.
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("config %s is not a mapping", path)
+	}
+	if raw == nil {
+		return map[string]any{}, nil
+	}
+
+	plugins, ok := raw["plugins"].([]any)
+	if !ok {
+		return raw, nil
+	}
+	for _, entry := range plugins {
+		if p, ok := entry.(map[string]any); ok && p["name"] == pluginName {
+			config, _ := p["config"].(map[string]any)
+			if config == nil {
+				config = map[string]any{}
+			}
+			return config, nil
+		}
+	}
+	return nil, fmt.Errorf("config %s has no %q plugin", path, pluginName)
+}
+
+// loadReleaseContext reads a JSON release context from path.
+func loadReleaseContext(path string) (plugin.ReleaseContext, error) {
+	var releaseCtx plugin.ReleaseContext
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return releaseCtx, fmt.Errorf("failed to read context: %w", err)
+	}
+	if err := json.Unmarshal(data, &releaseCtx); err != nil {
+		return releaseCtx, fmt.Errorf("failed to parse context %s: %w", path, err)
+	}
+	return releaseCtx, nil
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
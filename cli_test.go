@@ -0,0 +1,175 @@
+// Package main provides tests for the command line interface.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/plugin-slack/slacktest"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// writeTestFile writes content to name in a temporary directory and returns its path.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runTestCLI runs the command line interface and returns the exit code and output.
+func runTestCLI(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := runCLI(context.Background(), &SlackPlugin{}, args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestCLIPreview tests previews from a Relicta configuration and a context file.
+func TestCLIPreview(t *testing.T) {
+	at := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	config := writeTestFile(t, "release.config.yaml", `
+plugins:
+  - name: github
+    config:
+      draft: false
+  - name: slack
+    config:
+      bot_token: "xoxb-XXXXXXXX"
+      channel: "#releases"
+      schedule_at: `+at+`
+      state_dir: "`+t.TempDir()+`"
+`)
+	releaseCtx := writeTestFile(t, "context.json", `{"version": "1.2.0", "release_type": "minor", "branch": "main"}`)
+
+	for _, args := range [][]string{
+		{"preview", "--config", config, "--context", releaseCtx},
+		{"send", "--dry-run", "--config", config, "--context", releaseCtx},
+	} {
+		code, stdout, stderr := runTestCLI(args...)
+		if code != exitOK {
+			t.Fatalf("%v: expected exit 0, got %d: %s%s", args, code, stdout, stderr)
+		}
+		var resp plugin.ExecuteResponse
+		if err := json.Unmarshal([]byte(stdout), &resp); err != nil {
+			t.Fatalf("%v: expected a JSON response, got %q", args, stdout)
+		}
+		if !resp.Success || !strings.Contains(resp.Message, "Would send") {
+			t.Errorf("%v: expected a dry run, got %+v", args, resp)
+		}
+		if resp.Outputs["delivery_at"] != at {
+			t.Errorf("%v: expected the YAML timestamp to be passed as a string, got %v", args, resp.Outputs["delivery_at"])
+		}
+		previews, _ := resp.Outputs["previews"].([]any)
+		if len(previews) != 1 || !strings.Contains(previews[0].(map[string]any)["text"].(string), "1.2.0") {
+			t.Errorf("%v: unexpected previews %v", args, resp.Outputs["previews"])
+		}
+		if strings.Contains(stdout, "XXXXXXXX") {
+			t.Errorf("%v: expected the token to be redacted", args)
+		}
+	}
+}
+
+// TestCLISend tests sending through the Web API.
+func TestCLISend(t *testing.T) {
+	calls, mu := recordingSlackAPI(t, nil)
+	config := writeTestFile(t, "slack.json", `{"bot_token": "xoxb-test", "channel": "#releases", "state_dir": "`+t.TempDir()+`"}`)
+
+	code, stdout, stderr := runTestCLI("send", "--config", config, "--hook", "on-error")
+	if code != exitOK {
+		t.Fatalf("expected exit 0, got %d: %s%s", code, stdout, stderr)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(*calls) != 2 || (*calls)[0].Method != "chat.postMessage" || (*calls)[1].Method != "chat.getPermalink" {
+		t.Errorf("expected a chat.postMessage call and its permalink, got %v", *calls)
+	}
+	if strings.Contains(stdout, "xoxb-test") {
+		t.Error("expected the token to be redacted")
+	}
+}
+
+// TestCLIValidate tests the validate command.
+func TestCLIValidate(t *testing.T) {
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+
+	valid := writeTestFile(t, "valid.yaml", "webhook: https://hooks.slack.com/services/T000/B000/XXXXXXXX\n")
+	code, stdout, _ := runTestCLI("validate", "--config", valid)
+	if code != exitOK || !strings.Contains(stdout, "configuration is valid") {
+		t.Errorf("expected a valid configuration, got %d: %s", code, stdout)
+	}
+
+	invalid := writeTestFile(t, "invalid.yaml", "webhook: https://example.com/hook\nchanel: \"#releases\"\n")
+	code, stdout, _ = runTestCLI("validate", "--config", invalid)
+	if code != exitFailure {
+		t.Errorf("expected exit 1, got %d", code)
+	}
+	for _, want := range []string{"webhook: ", "[format]", "chanel: ", `did you mean "channel"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in %q", want, stdout)
+		}
+	}
+}
+
+// TestCLIUsage tests usage errors.
+func TestCLIUsage(t *testing.T) {
+	tests := []struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		{nil, exitUsage, "Usage:"},
+		{[]string{"deploy"}, exitUsage, `unknown command "deploy"`},
+		{[]string{"preview", "--bogus"}, exitUsage, "flag provided but not defined"},
+		{[]string{"preview", "extra"}, exitUsage, "unexpected arguments: extra"},
+		{[]string{"validate", "--config", "/does/not/exist.yaml"}, exitFailure, "failed to read config"},
+		{[]string{"preview", "--config", "missing.yaml"}, exitFailure, "failed to read config"},
+		{[]string{"help"}, exitOK, ""},
+	}
+	for _, tt := range tests {
+		code, _, stderr := runTestCLI(tt.args...)
+		if code != tt.code || !strings.Contains(stderr, tt.stderr) {
+			t.Errorf("%v: expected exit %d with %q, got %d with %q", tt.args, tt.code, tt.stderr, code, stderr)
+		}
+	}
+
+	config := writeTestFile(t, "release.config.yaml", "plugins:\n  - name: github\n")
+	if code, _, stderr := runTestCLI("validate", "--config", config); code != exitFailure || !strings.Contains(stderr, `no "slack" plugin`) {
+		t.Errorf("expected a missing plugin error, got %d: %s", code, stderr)
+	}
+}
+
+// TestCLISlackURL tests pointing the commands at a fake Slack server.
+func TestCLISlackURL(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+	config := writeTestFile(t, "slack.yaml", "webhook: "+testWebhookURL+"\n")
+
+	code, stdout, stderr := runTestCLI("test-webhook", "--config", config, "--slack-url", server.URL)
+	if code != exitOK || !strings.Contains(stdout, "destination is reachable") {
+		t.Errorf("expected a reachable webhook, got %d: %s%s", code, stdout, stderr)
+	}
+
+	server.FailNext(slacktest.Webhook, slacktest.Failure{Status: http.StatusNotFound, Error: "no_service"})
+	code, stdout, _ = runTestCLI("test-webhook", "--config", config, "--slack-url", server.URL)
+	if code != exitFailure || !strings.Contains(stdout, "revoked or deleted") {
+		t.Errorf("expected a revoked webhook, got %d: %s", code, stdout)
+	}
+
+	code, stdout, stderr = runTestCLI("send", "--config", config, "--slack-url", server.URL, "--hook", "on-success")
+	if code != exitOK {
+		t.Fatalf("expected exit 0, got %d: %s%s", code, stdout, stderr)
+	}
+	if calls := server.Calls(slacktest.Webhook); len(calls) != 3 || calls[2].Body["attachments"] == nil {
+		t.Errorf("expected the notification to reach the fake, got %+v", calls)
+	}
+}
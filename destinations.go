@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// Destination is an additional place notifications are sent to.
+// Unset fields inherit the top-level settings.
+type Destination struct {
+	// Name keys the destination's outputs; empty uses its channel.
+	Name string `json:"name,omitempty"`
+	// Webhook posts to this webhook instead of using the bot token.
+	Webhook string `json:"webhook,omitempty"`
+	// Channel overrides the channel.
+	Channel string `json:"channel,omitempty"`
+	// Locale overrides the notification language.
+	Locale string `json:"locale,omitempty"`
+}
+
+// parseDestinations parses the destinations list. Entries that are not
+// objects are skipped; Validate reports them.
+func parseDestinations(raw any) []Destination {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]Destination, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		parser := helpers.NewConfigParser(m)
+		out = append(out, Destination{
+			Name:    parser.GetString("name", "", ""),
+			Webhook: parser.GetString("webhook", "", ""),
+			Channel: parser.GetString("channel", "", ""),
+			Locale:  parser.GetString("locale", "", ""),
+		})
+	}
+	return out
+}
+
+// targets returns the configuration for every place a notification goes:
+// the top-level destination first, then each configured destination with
+// its overrides applied.
+func (c *Config) targets() []*Config {
+	out := []*Config{c}
+	for _, d := range c.Destinations {
+		t := *c
+		t.Destinations = nil
+		if d.Webhook != "" {
+			t.WebhookURL = d.Webhook
+			t.BotToken = ""
+		}
+		if d.Channel != "" {
+			t.Channel = d.Channel
+		}
+		if d.Locale != "" {
+			t.Locale = d.Locale
+		}
+		out = append(out, &t)
+	}
+	return out
+}
+
+// destinationName names the target in previews, outputs and the audit log:
+// its channel, or "webhook" for the webhook's default channel.
+func (c *Config) destinationName() string {
+	if c.Channel == "" {
+		return "webhook"
+	}
+	return c.Channel
+}
+
+// outputKeys returns the keys of the targets' outputs, in the order of
+// targets: a destination's name, or else its destinationName. A key that is
+// already taken falls back to the destination's config path, such as
+// "destinations[1]", so every key is unique and stable.
+func (c *Config) outputKeys() []string {
+	targets := c.targets()
+	keys := make([]string, len(targets))
+	used := make(map[string]bool, len(targets))
+	for i, t := range targets {
+		key := t.destinationName()
+		if i > 0 && c.Destinations[i-1].Name != "" {
+			key = c.Destinations[i-1].Name
+		}
+		if used[key] {
+			key = fmt.Sprintf("destinations[%d]", i-1)
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
+// delivery is the result of sending a message to one target.
+type delivery struct {
+	// Key names the delivery in the destinations output.
+	Key         string
+	Destination string
+	// Posted is nil when the delivery failed.
+	Posted *postedMessage
+	// Text is the plain-text rendering of the message.
+	Text      string
+	Scheduled bool
+	Err       error
+}
+
+// deliveries are the results of a fan-out, the top-level destination first.
+type deliveries []delivery
+
+// primary returns the message posted to the top-level destination, or nil
+// if that delivery failed.
+func (ds deliveries) primary() *postedMessage {
+	if len(ds) == 0 {
+		return nil
+	}
+	return ds[0].Posted
+}
+
+// outputs describes the sent messages for downstream plugins: a
+// destinations map keyed by outputKeys, plus the identifiers of the
+// top-level message.
+func (ds deliveries) outputs() map[string]any {
+	destinations := make(map[string]any, len(ds))
+	for _, d := range ds {
+		m := map[string]any{
+			"destination": d.Destination,
+			"text":        d.Text,
+		}
+		if d.Posted != nil {
+			d.Posted.addOutputs(m)
+		}
+		if d.Scheduled {
+			m["scheduled"] = true
+		}
+		if d.Err != nil {
+			m["error"] = d.Err.Error()
+		}
+		destinations[d.Key] = m
+	}
+	outputs := map[string]any{"destinations": destinations}
+	if posted := ds.primary(); posted != nil {
+		posted.addOutputs(outputs)
+	}
+	return outputs
+}
+
+// addOutputs sets the channel_id, ts and permalink outputs that are known.
+func (m *postedMessage) addOutputs(outputs map[string]any) {
+	for key, value := range map[string]string{"channel_id": m.Channel, "ts": m.TS, "permalink": m.Permalink} {
+		if value != "" {
+			outputs[key] = value
+		}
+	}
+}
+
+// fanOut sends a message built for each target and returns the result of
+// every delivery, together with every delivery error. Messages posted with
+// a bot token get their permalink.
+func (p *SlackPlugin) fanOut(ctx context.Context, cfg *Config, plan deliveryPlan, build func(target *Config) (SlackMessage, error)) (deliveries, error) {
+	var (
+		ds   deliveries
+		errs []error
+	)
+	keys := cfg.outputKeys()
+	for i, target := range cfg.targets() {
+		msg, err := buildPayload(ctx, target, build)
+		var posted *postedMessage
+		if err == nil {
+			posted, err = p.dispatch(ctx, target, msg, plan)
+		}
+		if err == nil && target.BotToken != "" && posted.TS != "" {
+			// The message is out; a missing link must not fail it.
+			if posted.Permalink, err = p.permalink(ctx, target.BotToken, posted); err != nil {
+				logger(ctx).Warn("failed to get message permalink", "destination", target.destinationName(), "error", err)
+				err = nil
+			}
+		}
+		p.auditDelivery(ctx, target, msg, plan.Scheduled, posted, err)
+
+		ds = append(ds, delivery{
+			Key:         keys[i],
+			Destination: target.destinationName(),
+			Posted:      posted,
+			Text:        plainTextRendering(msg),
+			Scheduled:   err == nil && plan.Scheduled && target.BotToken != "",
+			Err:         err,
+		})
+		switch {
+		case err != nil && i == 0:
+			errs = append(errs, err)
+		case err != nil:
+			errs = append(errs, fmt.Errorf("destinations[%d]: %w", i-1, err))
+		}
+	}
+	return ds, errors.Join(errs...)
+}
+
+// buildPayload builds the message for target and checks it against Slack's
+// limits, logging the repairs made.
+func buildPayload(ctx context.Context, target *Config, build func(target *Config) (SlackMessage, error)) (SlackMessage, error) {
+	msg, err := build(target)
+	if err != nil {
+		return msg, err
+	}
+	msg, fixes, err := target.preparePayload(msg)
+	for _, fix := range fixes {
+		logger(ctx).Info("adjusted payload for Slack's limits", "fix", fix)
+	}
+	return msg, err
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+	"golang.org/x/text/cases"
+)
+
+// Plugin modes.
+const (
+	// modeNotify sends release notifications for each hook.
+	modeNotify = "notify"
+	// modeDigest posts a summary of recorded releases instead.
+	modeDigest = "digest"
+)
+
+// digestHook is the only hook that posts the digest in digest mode, so a
+// scheduled job posts it once. It is the default hook of the send command.
+const digestHook = plugin.HookPostPublish
+
+// releaseTypeOrder is the order release types are counted in a digest.
+// Other types follow alphabetically.
+var releaseTypeOrder = []string{"major", "minor", "patch"}
+
+// releaseDigest summarizes the successful releases in a time window.
+type releaseDigest struct {
+	Since, Until time.Time
+	// Releases are the successful releases, oldest first.
+	Releases []historyEntry
+}
+
+// versions returns the versions of releases matching keep.
+func (d releaseDigest) versions(keep func(historyEntry) bool) []string {
+	var out []string
+	for _, e := range d.Releases {
+		if keep(e) {
+			out = append(out, e.Version)
+		}
+	}
+	return out
+}
+
+// typeCounts returns the number of releases per release type in display order.
+func (d releaseDigest) typeCounts() ([]string, map[string]int) {
+	counts := map[string]int{}
+	for _, e := range d.Releases {
+		if t := strings.ToLower(e.ReleaseType); t != "" {
+			counts[t]++
+		}
+	}
+
+	var types []string
+	for _, t := range releaseTypeOrder {
+		if counts[t] > 0 {
+			types = append(types, t)
+		}
+	}
+	var other []string
+	for t := range counts {
+		if !inList(releaseTypeOrder, t) {
+			other = append(other, t)
+		}
+	}
+	sort.Strings(other)
+	return append(types, other...), counts
+}
+
+// inList reports whether s is in list.
+func inList(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// loadDigest reads the successful releases of the days before now.
+func loadDigest(ctx context.Context, cfg *Config, now time.Time) (releaseDigest, error) {
+	d := releaseDigest{Since: now.AddDate(0, 0, -cfg.DigestDays), Until: now}
+	entries, err := readHistory(ctx, cfg.StateDir, d.Since, d.Until)
+	if err != nil {
+		return d, err
+	}
+	for _, e := range entries {
+		if e.Outcome == outcomeSuccess {
+			d.Releases = append(d.Releases, e)
+		}
+	}
+	return d, nil
+}
+
+// buildDigestMessage builds the digest in the target's language.
+func buildDigestMessage(cfg *Config, d releaseDigest) SlackMessage {
+	pr := cfg.printer()
+	lang := resolveLocale(cfg.Locale)
+
+	text := translate(pr, "Releases from %s to %s", formatDate(pr, d.Since.UTC()), formatDate(pr, d.Until.UTC()))
+	if len(d.Releases) == 0 {
+		text += "\n" + translate(pr, "No releases in this period.")
+	}
+
+	var fields []Field
+	if len(d.Releases) > 0 {
+		types, counts := d.typeCounts()
+		perType := make([]string, 0, len(types))
+		for _, t := range types {
+			perType = append(perType, fmt.Sprintf("%s: %d", cases.Title(lang).String(releaseTypeName(pr, t)), counts[t]))
+		}
+		fields = append(fields,
+			Field{Title: translate(pr, "Releases"), Value: translate(pr, "%d releases", len(d.Releases)), Short: true},
+			Field{Title: translate(pr, "Release Type"), Value: strings.Join(perType, ", "), Short: true},
+		)
+		if breaking := d.versions(func(e historyEntry) bool { return e.Breaking > 0 }); len(breaking) > 0 {
+			fields = append(fields, Field{Title: translate(pr, "Breaking Releases"), Value: strings.Join(breaking, ", ")})
+		}
+		all := d.versions(func(historyEntry) bool { return true })
+		fields = append(fields, Field{Title: translate(pr, "Versions"), Value: strings.Join(all, ", ")})
+	}
+
+	return cfg.composeMessage("", Attachment{
+		Color:  "#439FE0",
+		Title:  ":calendar: " + translate(pr, "Release Digest"),
+		Text:   text,
+		Fields: fields,
+		Footer: "Relicta",
+		Ts:     d.Until.Unix(),
+	})
+}
+
+// sendDigest posts the summary of the releases recorded in the digest window.
+func (p *SlackPlugin) sendDigest(ctx context.Context, cfg *Config, now time.Time, dryRun bool) (*plugin.ExecuteResponse, error) {
+	d, err := loadDigest(ctx, cfg, now)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	build := func(target *Config) (SlackMessage, error) {
+		return buildDigestMessage(target, d), nil
+	}
+
+	if dryRun {
+		return p.previewResponse(cfg, "digest", "Would send Slack release digest", map[string]any{
+			"releases": len(d.Releases),
+			"versions": d.versions(func(historyEntry) bool { return true }),
+			"since":    d.Since.UTC().Format(time.RFC3339),
+		}, build), nil
+	}
+
+	sent, err := p.fanOut(ctx, cfg, deliveryPlan{Now: now, At: now}, build)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to send Slack message: %v", err),
+			Outputs: sent.outputs(),
+		}, nil
+	}
+
+	outputs := sent.outputs()
+	outputs["releases"] = len(d.Releases)
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: "Sent Slack release digest",
+		Outputs: outputs,
+	}, nil
+}
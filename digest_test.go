@@ -0,0 +1,101 @@
+// Package main provides tests for the release digest.
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestBuildDigestMessage tests the digest layout.
+func TestBuildDigestMessage(t *testing.T) {
+	d := releaseDigest{
+		Since: time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC),
+		Releases: []historyEntry{
+			{Version: "1.3.0", ReleaseType: "minor"},
+			{Version: "2.0.0", ReleaseType: "major", Breaking: 2},
+			{Version: "2.0.1", ReleaseType: "patch"},
+			{Version: "2.0.2", ReleaseType: "patch"},
+		},
+	}
+
+	msg := buildDigestMessage(&Config{}, d)
+	att := msg.Attachments[0]
+	if att.Title != ":calendar: Release Digest" || att.Text != "Releases from Mar 1, 2024 to Mar 8, 2024" {
+		t.Errorf("unexpected title/text %q / %q", att.Title, att.Text)
+	}
+	want := []Field{
+		{Title: "Releases", Value: "4 releases", Short: true},
+		{Title: "Release Type", Value: "Major: 1, Minor: 1, Patch: 2", Short: true},
+		{Title: "Breaking Releases", Value: "2.0.0"},
+		{Title: "Versions", Value: "1.3.0, 2.0.0, 2.0.1, 2.0.2"},
+	}
+	if len(att.Fields) != len(want) {
+		t.Fatalf("expected %d fields, got %+v", len(want), att.Fields)
+	}
+	for i := range want {
+		if att.Fields[i] != want[i] {
+			t.Errorf("field %d: expected %+v, got %+v", i, want[i], att.Fields[i])
+		}
+	}
+
+	msg = buildDigestMessage(&Config{Locale: "de"}, releaseDigest{Since: d.Since, Until: d.Until})
+	if text := msg.Attachments[0].Text; text != "Releases vom 01.03.2024 bis 08.03.2024\nKeine Releases in diesem Zeitraum." {
+		t.Errorf("unexpected empty digest text %q", text)
+	}
+}
+
+// TestDigestMode tests recording releases and posting the digest end to end.
+func TestDigestMode(t *testing.T) {
+	calls, _ := recordingSlackAPI(t, nil)
+	dir := t.TempDir()
+	execute := func(hook plugin.Hook, config map[string]any, releaseCtx plugin.ReleaseContext) *plugin.ExecuteResponse {
+		t.Helper()
+		config["bot_token"] = "xoxb-1-2-3"
+		config["channel"] = "#releases"
+		config["state_dir"] = dir
+		resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{Hook: hook, Config: config, Context: releaseCtx})
+		if err != nil || !resp.Success {
+			t.Fatalf("expected success, got %v / %+v", err, resp)
+		}
+		return resp
+	}
+
+	execute(plugin.HookOnSuccess, map[string]any{"history": true, "notify_on_success": false},
+		plugin.ReleaseContext{Version: "1.1.0", ReleaseType: "minor"})
+	execute(plugin.HookOnError, map[string]any{"history": true, "notify_on_error": false},
+		plugin.ReleaseContext{Version: "1.2.0", ReleaseType: "minor"})
+
+	// Only the digest hook posts the digest; the release hooks are ignored.
+	digest := map[string]any{"mode": "digest", "digest_days": 1}
+	hooks := []plugin.Hook{plugin.HookPreInit, plugin.HookPostPlan, plugin.HookPrePublish, plugin.HookPostPublish, plugin.HookOnSuccess, plugin.HookOnError}
+	for _, hook := range hooks {
+		resp := execute(hook, digest, plugin.ReleaseContext{Version: "1.3.0", ReleaseType: "minor"})
+		if hook == digestHook {
+			if resp.Outputs["releases"] != 1 {
+				t.Errorf("unexpected response %+v", resp)
+			}
+		} else if resp.Message != fmt.Sprintf("Hook %s not handled in digest mode", hook) {
+			t.Errorf("%s: expected no digest, got %+v", hook, resp)
+		}
+	}
+
+	var posts []apiCall
+	for _, c := range *calls {
+		if c.Method == "chat.postMessage" {
+			posts = append(posts, c)
+		}
+	}
+	if len(posts) != 1 {
+		t.Fatalf("expected only the digest to be posted, got %+v", posts)
+	}
+	att := posts[0].Body["attachments"].([]any)[0].(map[string]any)
+	versions := att["fields"].([]any)[2].(map[string]any)
+	if att["title"] != ":calendar: Release Digest" || versions["value"] != "1.1.0" {
+		t.Errorf("unexpected digest %v", att)
+	}
+}
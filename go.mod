@@ -3,14 +3,15 @@ module github.com/relicta-tech/plugin-slack
 go 1.24.0
 
 require (
+	github.com/hashicorp/go-hclog v0.14.1
 	github.com/relicta-tech/relicta-plugin-sdk v1.0.0
 	golang.org/x/text v0.32.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/fatih/color v1.7.0 // indirect
 	github.com/golang/protobuf v1.5.4 // indirect
-	github.com/hashicorp/go-hclog v0.14.1 // indirect
 	github.com/hashicorp/go-plugin v1.6.2 // indirect
 	github.com/hashicorp/yamux v0.1.1 // indirect
 	github.com/mattn/go-colorable v0.1.4 // indirect
@@ -0,0 +1,338 @@
+// Package main provides golden-file tests for message rendering.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// update rewrites the golden files: go test -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenClock is the fixed time the golden messages are rendered at.
+var goldenClock = time.Date(2024, time.March, 5, 14, 7, 0, 0, time.UTC)
+
+// goldenHooks are the notifications covered, keyed by file name prefix.
+var goldenHooks = []struct {
+	name string
+	hook plugin.Hook
+}{
+	{"start", plugin.HookPreInit},
+	{"plan", plugin.HookPostPlan},
+	{"publishing", plugin.HookPrePublish},
+	{"success", plugin.HookPostPublish},
+	{"error", plugin.HookOnError},
+}
+
+// goldenContexts are the releases rendered. The first six select each
+// built-in theme; the rest are edge cases.
+var goldenContexts = []struct {
+	name string
+	ctx  plugin.ReleaseContext
+}{
+	{"minor", plugin.ReleaseContext{
+		Version:         "1.3.0",
+		PreviousVersion: "1.2.4",
+		TagName:         "v1.3.0",
+		ReleaseType:     "minor",
+		RepositoryURL:   "https://github.com/acme/widgets",
+		RepositoryOwner: "acme",
+		RepositoryName:  "widgets",
+		Branch:          "main",
+		CommitSHA:       "4f2a9c1e8b7d6a5f4e3d2c1b0a9f8e7d6c5b4a39",
+		ReleaseNotes:    "## Features\n- Export widgets as CSV\n\n## Fixes\n- Keep sort order after refresh",
+		Changes: &plugin.CategorizedChanges{
+			Features: []plugin.ConventionalCommit{
+				{Hash: "a1b2c3d", Type: "feat", Scope: "export", Description: "export widgets as CSV"},
+			},
+			Fixes: []plugin.ConventionalCommit{
+				{Hash: "e4f5a6b", Type: "fix", Description: "keep sort order after refresh"},
+				{Hash: "c7d8e9f", Type: "fix", Description: "escape <script> & quotes in names"},
+			},
+			Docs: []plugin.ConventionalCommit{
+				{Hash: "0a1b2c3", Type: "docs", Description: "document the export format"},
+			},
+		},
+	}},
+	{"major", plugin.ReleaseContext{
+		Version:         "2.0.0",
+		PreviousVersion: "1.3.0",
+		TagName:         "v2.0.0",
+		ReleaseType:     "major",
+		RepositoryURL:   "https://github.com/acme/widgets",
+		Branch:          "main",
+		CommitSHA:       "9e8d7c6b5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d",
+		ReleaseNotes:    "## Breaking Changes\n- The v1 API has been removed",
+		Changes: &plugin.CategorizedChanges{
+			Breaking: []plugin.ConventionalCommit{
+				{Hash: "1a2b3c4", Type: "feat", Description: "remove the v1 API", Breaking: true, BreakingDescription: "Clients must use /v2"},
+			},
+			Features: []plugin.ConventionalCommit{
+				{Hash: "5d6e7f8", Type: "feat", Description: "streaming exports"},
+			},
+		},
+	}},
+	{"patch", plugin.ReleaseContext{
+		Version:         "1.2.5",
+		PreviousVersion: "1.2.4",
+		TagName:         "v1.2.5",
+		ReleaseType:     "patch",
+		Branch:          "release/1.2",
+		CommitSHA:       "abc1234",
+		Changes: &plugin.CategorizedChanges{
+			Fixes: []plugin.ConventionalCommit{
+				{Hash: "abc1234", Type: "fix", Description: "handle empty responses"},
+			},
+		},
+	}},
+	{"breaking", plugin.ReleaseContext{
+		Version:         "0.9.0",
+		PreviousVersion: "0.8.2",
+		TagName:         "v0.9.0",
+		ReleaseType:     "minor",
+		Branch:          "main",
+		CommitSHA:       "def5678",
+		Changes: &plugin.CategorizedChanges{
+			Breaking: []plugin.ConventionalCommit{
+				{Hash: "def5678", Type: "refactor", Description: "rename the config file", Breaking: true},
+			},
+		},
+	}},
+	{"prerelease", plugin.ReleaseContext{
+		Version:         "1.3.0-rc.1",
+		PreviousVersion: "1.2.4",
+		TagName:         "v1.3.0-rc.1",
+		ReleaseType:     "minor",
+		Branch:          "next",
+		CommitSHA:       "fed9876",
+		Changes: &plugin.CategorizedChanges{
+			Features: []plugin.ConventionalCommit{
+				{Hash: "fed9876", Type: "feat", Description: "preview the new dashboard"},
+			},
+		},
+	}},
+	{"default", plugin.ReleaseContext{
+		Version:     "1.3.0+build.42",
+		TagName:     "v1.3.0+build.42",
+		ReleaseType: "custom",
+		Branch:      "main",
+	}},
+	{"emoji", plugin.ReleaseContext{
+		Version:         "3.1.0",
+		PreviousVersion: "3.0.0",
+		TagName:         "v3.1.0",
+		ReleaseType:     "minor",
+		RepositoryName:  "🦄-widgets",
+		Branch:          "feature/🚀",
+		CommitSHA:       "0ff1ce0",
+		ReleaseNotes:    "🎉 Big release! 👨‍👩‍👧‍👦 family support, 🏳️‍🌈 flags and :tada: shortcodes",
+		Changes: &plugin.CategorizedChanges{
+			Features: []plugin.ConventionalCommit{
+				{Hash: "0ff1ce0", Type: "feat", Scope: "ui", Description: "add 🌙 dark mode"},
+			},
+		},
+	}},
+	{"rtl", plugin.ReleaseContext{
+		Version:         "1.4.0",
+		PreviousVersion: "1.3.0",
+		TagName:         "v1.4.0",
+		ReleaseType:     "minor",
+		RepositoryName:  "ווידג'טים",
+		Branch:          "ראשי",
+		CommitSHA:       "a1a1a1a",
+		ReleaseNotes:    "تحسينات في الأداء ودعم اللغة العربية\nשיפורי ביצועים with mixed English",
+		Changes: &plugin.CategorizedChanges{
+			Features: []plugin.ConventionalCommit{
+				{Hash: "a1a1a1a", Type: "feat", Description: "إضافة دعم الكتابة من اليمين إلى اليسار"},
+			},
+			Fixes: []plugin.ConventionalCommit{
+				{Hash: "b2b2b2b", Type: "fix", Description: "תיקון יישור הטקסט"},
+			},
+		},
+	}},
+	{"huge", hugeReleaseContext()},
+	{"empty", plugin.ReleaseContext{Version: "0.0.1"}},
+}
+
+// hugeReleaseContext returns a release whose notes, changelog and commit
+// lists exceed Slack's limits.
+func hugeReleaseContext() plugin.ReleaseContext {
+	changes := &plugin.CategorizedChanges{}
+	for i := 0; i < 40; i++ {
+		commit := plugin.ConventionalCommit{
+			Hash:        fmt.Sprintf("%07x", i),
+			Type:        "feat",
+			Description: fmt.Sprintf("feature %d %s", i, strings.Repeat("with a very long description ", 5)),
+		}
+		changes.Features = append(changes.Features, commit)
+		commit.Type = "fix"
+		changes.Fixes = append(changes.Fixes, commit)
+	}
+	var notes, changelog strings.Builder
+	for i := 0; i < 120; i++ {
+		fmt.Fprintf(&notes, "- Release note line %d with enough text to matter\n", i)
+		fmt.Fprintf(&changelog, "* change %d (%07x)\n", i, i)
+	}
+	return plugin.ReleaseContext{
+		Version:         "5.0.0",
+		PreviousVersion: "4.9.9",
+		TagName:         "v5.0.0",
+		ReleaseType:     "minor",
+		RepositoryURL:   "https://github.com/acme/" + strings.Repeat("very-long-repository-name-", 8),
+		RepositoryName:  strings.Repeat("very-long-repository-name-", 8),
+		Branch:          strings.Repeat("long-branch/", 20),
+		CommitSHA:       strings.Repeat("f", 40),
+		ReleaseNotes:    notes.String(),
+		Changelog:       changelog.String(),
+		Changes:         changes,
+	}
+}
+
+// goldenCases lists the golden files: every hook for a set of contexts in
+// English, every context for success notifications, and every locale for the
+// minor release.
+func goldenCases() []string {
+	locales := []string{"en", "de", "fr", "es", "ja", "pt"}
+	var names []string
+	for _, h := range goldenHooks {
+		for _, c := range goldenContexts {
+			switch c.name {
+			case "minor":
+				for _, locale := range locales {
+					names = append(names, h.name+"-"+c.name+"-"+locale)
+				}
+			case "emoji", "rtl", "huge", "empty":
+				names = append(names, h.name+"-"+c.name+"-en")
+			default:
+				if h.name == "success" {
+					names = append(names, h.name+"-"+c.name+"-en")
+				}
+			}
+		}
+	}
+	return names
+}
+
+// TestGolden renders every notification variant in both formats and compares
+// the previews with testdata/golden. Run with -update after intended changes
+// and review the JSON diff.
+func TestGolden(t *testing.T) {
+	for _, name := range goldenCases() {
+		t.Run(name, func(t *testing.T) {
+			got := renderGolden(t, name)
+			path := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, got, 0o600); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("missing golden file, run go test -run TestGolden -update: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from the rendering; run go test -run TestGolden -update and review the diff:\n%s", path, firstDifference(want, got))
+			}
+		})
+	}
+}
+
+// renderGolden renders the case name, "<hook>-<context>-<locale>", in the
+// attachments and blocks formats as indented JSON.
+func renderGolden(t *testing.T, name string) []byte {
+	t.Helper()
+	parts := strings.SplitN(name, "-", 3)
+	var hook plugin.Hook
+	for _, h := range goldenHooks {
+		if h.name == parts[0] {
+			hook = h.hook
+		}
+	}
+	var releaseCtx plugin.ReleaseContext
+	for _, c := range goldenContexts {
+		if c.name == parts[1] {
+			releaseCtx = c.ctx
+		}
+	}
+
+	rendered := map[string]any{}
+	for _, format := range []string{formatAttachments, formatBlocks} {
+		config := map[string]any{
+			"webhook":              testWebhookURL,
+			"channel":              "#releases",
+			"mentions":             []any{"@here"},
+			"format":               format,
+			"locale":               parts[2],
+			"include_changelog":    true,
+			"notify_on_start":      true,
+			"notify_on_plan":       true,
+			"notify_on_publishing": true,
+		}
+		if parts[1] == "huge" {
+			// A title beyond the header limit shows the payload repairs.
+			config["themes"] = map[string]any{themeMinor: map[string]any{"title": "{{.Version}} from {{.Branch}}"}}
+		}
+
+		p := NewSlackPlugin(WithClock(func() time.Time { return goldenClock }), WithStateDir(t.TempDir()))
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    hook,
+			Config:  config,
+			Context: releaseCtx,
+			DryRun:  true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Success {
+			t.Fatalf("%s: %s", format, resp.Error)
+		}
+		previews, _ := resp.Outputs["previews"].([]any)
+		for _, preview := range previews {
+			// The builder link repeats the payload.
+			delete(preview.(map[string]any), "builder_url")
+		}
+		rendered[format] = previews
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rendered); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// firstDifference shows the first differing line of two renderings.
+func firstDifference(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, w, g)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// historyFileName is the name of the release history inside the state directory.
+const historyFileName = "history.jsonl"
+
+// Release outcomes recorded in the history.
+const (
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// historyEntry is one line of the release history.
+type historyEntry struct {
+	Time            time.Time `json:"time"`
+	Version         string    `json:"version"`
+	PreviousVersion string    `json:"previous_version,omitempty"`
+	TagName         string    `json:"tag_name,omitempty"`
+	ReleaseType     string    `json:"release_type,omitempty"`
+	Stability       string    `json:"stability"`
+	Branch          string    `json:"branch,omitempty"`
+	Repository      string    `json:"repository,omitempty"`
+	Outcome         string    `json:"outcome"`
+	Features        int       `json:"features"`
+	Fixes           int       `json:"fixes"`
+	Breaking        int       `json:"breaking"`
+	// Commits counts all categorized commits.
+	Commits int `json:"commits"`
+}
+
+// newHistoryEntry describes a finished release.
+func newHistoryEntry(releaseCtx plugin.ReleaseContext, outcome string, at time.Time) historyEntry {
+	e := historyEntry{
+		Time:            at.UTC(),
+		Version:         releaseCtx.Version,
+		PreviousVersion: releaseCtx.PreviousVersion,
+		TagName:         releaseCtx.TagName,
+		ReleaseType:     releaseCtx.ReleaseType,
+		Stability:       releaseStability(releaseCtx),
+		Branch:          releaseCtx.Branch,
+		Outcome:         outcome,
+	}
+	if releaseCtx.RepositoryOwner != "" && releaseCtx.RepositoryName != "" {
+		e.Repository = releaseCtx.RepositoryOwner + "/" + releaseCtx.RepositoryName
+	}
+	if c := releaseCtx.Changes; c != nil {
+		e.Features = len(c.Features)
+		e.Fixes = len(c.Fixes)
+		e.Breaking = len(c.Breaking)
+		e.Commits = len(c.Features) + len(c.Fixes) + len(c.Breaking) + len(c.Performance) +
+			len(c.Refactor) + len(c.Docs) + len(c.Other)
+	}
+	return e
+}
+
+// appendHistory appends an entry to the release history in dir.
+func appendHistory(dir string, e historyEntry) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, historyFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// readHistory returns the entries recorded in [since, until), oldest first.
+// A missing file yields no entries. Lines that cannot be decoded, such as a
+// line cut short by a crash, are skipped.
+func readHistory(ctx context.Context, dir string, since, until time.Time) ([]historyEntry, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	f, err := os.Open(filepath.Join(dir, historyFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			logger(ctx).Warn("skipping history line", "line", n, "error", err)
+			continue
+		}
+		if !e.Time.Before(since) && e.Time.Before(until) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// recordRelease appends the outcome of a release to the history when enabled.
+// Recording never fails a release: errors are only logged.
+func recordRelease(ctx context.Context, cfg *Config, hook plugin.Hook, releaseCtx plugin.ReleaseContext, at time.Time) {
+	if !cfg.History {
+		return
+	}
+	outcome := outcomeSuccess
+	switch hook {
+	case plugin.HookOnSuccess:
+	case plugin.HookOnError:
+		outcome = outcomeFailure
+	default:
+		return
+	}
+	if err := appendHistory(cfg.StateDir, newHistoryEntry(releaseCtx, outcome, at)); err != nil {
+		logger(ctx).Warn("failed to record release history", "error", err)
+	}
+}
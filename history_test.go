@@ -0,0 +1,81 @@
+// Package main provides tests for the release history.
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestHistory tests appending to and reading the release history.
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if entries, err := readHistory(context.Background(), dir, base, base.Add(time.Hour)); err != nil || entries != nil {
+		t.Fatalf("expected empty history, got %v / %v", entries, err)
+	}
+
+	releaseCtx := plugin.ReleaseContext{
+		Version:         "1.3.0",
+		ReleaseType:     "minor",
+		RepositoryOwner: "acme",
+		RepositoryName:  "app",
+		Changes: &plugin.CategorizedChanges{
+			Features: []plugin.ConventionalCommit{{}, {}},
+			Fixes:    []plugin.ConventionalCommit{{}},
+			Docs:     []plugin.ConventionalCommit{{}},
+		},
+	}
+	for i, v := range []string{"1.2.0", "1.3.0", "1.4.0-rc.1"} {
+		releaseCtx.Version = v
+		if err := appendHistory(dir, newHistoryEntry(releaseCtx, outcomeSuccess, base.AddDate(0, 0, i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A line cut short by a crash is skipped.
+	f, err := os.OpenFile(filepath.Join(dir, historyFileName), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"version": "1.5`)
+	_ = f.Close()
+
+	entries, err := readHistory(context.Background(), dir, base.AddDate(0, 0, 1), base.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Version != "1.3.0" || entries[1].Version != "1.4.0-rc.1" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	e := entries[1]
+	if e.Repository != "acme/app" || e.Stability != stabilityPrerelease || e.Features != 2 || e.Fixes != 1 || e.Commits != 4 {
+		t.Errorf("unexpected entry %+v", e)
+	}
+}
+
+// TestRecordRelease tests which hooks are recorded.
+func TestRecordRelease(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{History: true, StateDir: dir}
+	now := time.Now()
+	releaseCtx := plugin.ReleaseContext{Version: "2.0.0"}
+
+	recordRelease(context.Background(), cfg, plugin.HookPostPublish, releaseCtx, now)
+	recordRelease(context.Background(), cfg, plugin.HookOnSuccess, releaseCtx, now)
+	recordRelease(context.Background(), cfg, plugin.HookOnError, releaseCtx, now)
+	recordRelease(context.Background(), &Config{StateDir: dir}, plugin.HookOnSuccess, releaseCtx, now)
+
+	entries, err := readHistory(context.Background(), dir, now.Add(-time.Minute), now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Outcome != outcomeSuccess || entries[1].Outcome != outcomeFailure {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// localeFiles holds the message catalogs, one JSON file per language.
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// defaultLocale is used when no locale is configured or none matches.
+var defaultLocale = language.English
+
+// timestampLayout is the message ID of the per-locale time.Format layout.
+const timestampLayout = "Jan 2, 2006 15:04 MST"
+
+// dateLayout is the message ID of the per-locale date layout.
+const dateLayout = "Jan 2, 2006"
+
+// pluralSelectors lists the accepted plural forms in the order they are tried.
+// Exact matches ("=0", "=1", ...) come first, then CLDR plural categories.
+var pluralSelectors = []string{"zero", "one", "two", "few", "many", "other"}
+
+// localeCatalog is the on-disk format of a message catalog. Message values
+// are either a translation or an object of plural forms for a "%d" message.
+type localeCatalog struct {
+	Language string                     `json:"language"`
+	Messages map[string]json.RawMessage `json:"messages"`
+}
+
+var (
+	messageCatalog   catalog.Catalog
+	supportedLocales []language.Tag
+	localeMatcher    language.Matcher
+)
+
+func init() {
+	cat, tags, err := loadCatalogs(localeFiles)
+	if err != nil {
+		panic(fmt.Sprintf("slack: invalid message catalog: %v", err))
+	}
+	messageCatalog, supportedLocales = cat, tags
+	localeMatcher = language.NewMatcher(tags)
+}
+
+// loadCatalogs builds a catalog from every locales/*.json file in fsys.
+// The default locale is listed first so the matcher falls back to it.
+func loadCatalogs(fsys fs.FS) (catalog.Catalog, []language.Tag, error) {
+	files, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b := catalog.NewBuilder(catalog.Fallback(defaultLocale))
+	tags := []language.Tag{defaultLocale}
+	for _, name := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var lc localeCatalog
+		if err := json.Unmarshal(data, &lc); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path.Base(name), err)
+		}
+		tag, err := language.Parse(lc.Language)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path.Base(name), err)
+		}
+
+		for key, raw := range lc.Messages {
+			msg, err := catalogMessage(raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: message %q: %w", path.Base(name), key, err)
+			}
+			if err := b.Set(tag, key, msg); err != nil {
+				return nil, nil, fmt.Errorf("%s: message %q: %w", path.Base(name), key, err)
+			}
+		}
+		if tag != defaultLocale {
+			tags = append(tags, tag)
+		}
+	}
+	return b, tags, nil
+}
+
+// catalogMessage decodes a catalog value into a translation or plural selection.
+func catalogMessage(raw json.RawMessage) (catalog.Message, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return catalog.String(text), nil
+	}
+
+	var forms map[string]string
+	if err := json.Unmarshal(raw, &forms); err != nil {
+		return nil, fmt.Errorf("must be a string or an object of plural forms")
+	}
+	if _, ok := forms["other"]; !ok {
+		return nil, fmt.Errorf(`plural forms must include "other"`)
+	}
+
+	var exact []string
+	for sel := range forms {
+		if strings.HasPrefix(sel, "=") {
+			exact = append(exact, sel)
+		} else if !isPluralCategory(sel) {
+			return nil, fmt.Errorf("unknown plural form %q", sel)
+		}
+	}
+	sort.Strings(exact)
+
+	var cases []any
+	for _, sel := range append(exact, pluralSelectors...) {
+		if text, ok := forms[sel]; ok {
+			cases = append(cases, sel, text)
+		}
+	}
+	return plural.Selectf(1, "%d", cases...), nil
+}
+
+// isPluralCategory reports whether sel is a CLDR plural category.
+func isPluralCategory(sel string) bool {
+	for _, c := range pluralSelectors {
+		if c == sel {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveLocale returns the supported locale closest to a configured one.
+func resolveLocale(locale string) language.Tag {
+	if locale == "" {
+		return defaultLocale
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return defaultLocale
+	}
+	_, index, confidence := localeMatcher.Match(tag)
+	if confidence == language.No {
+		return defaultLocale
+	}
+	return supportedLocales[index]
+}
+
+// newPrinter returns a printer for a configured locale.
+func newPrinter(locale string) *message.Printer {
+	return message.NewPrinter(resolveLocale(locale), message.Catalog(messageCatalog))
+}
+
+// printer returns the message printer for the configured locale.
+func (c *Config) printer() *message.Printer {
+	return newPrinter(c.Locale)
+}
+
+// translate looks up a message by its English text. Keys without a
+// translation are formatted as is.
+func translate(pr *message.Printer, key string, args ...any) string {
+	return pr.Sprintf(message.Key(key, key), args...)
+}
+
+// releaseTypeKey returns the catalog message ID of a release type. Release
+// data is never used as a message ID itself, since the printer would read it
+// as a format string.
+func releaseTypeKey(releaseType string) (string, bool) {
+	switch releaseType {
+	case "major":
+		return "major", true
+	case "minor":
+		return "minor", true
+	case "patch":
+		return "patch", true
+	}
+	return "", false
+}
+
+// releaseTypeName returns the localized name of a release type. Types
+// without a catalog entry are returned unchanged.
+func releaseTypeName(pr *message.Printer, releaseType string) string {
+	key, ok := releaseTypeKey(releaseType)
+	if !ok {
+		return releaseType
+	}
+	return translate(pr, key)
+}
+
+// formatTimestamp formats t with the locale's timestamp layout.
+func formatTimestamp(pr *message.Printer, t time.Time) string {
+	return t.Format(translate(pr, timestampLayout))
+}
+
+// formatDate formats the date of t with the locale's date layout.
+func formatDate(pr *message.Printer, t time.Time) string {
+	return t.Format(translate(pr, dateLayout))
+}
+
+// validateLocale reports whether locale is well-formed and supported.
+// A well-formed but unsupported locale is a warning: English is used instead.
+func validateLocale(locale string) (supported bool, err error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return false, fmt.Errorf("locale %q is not a valid BCP 47 language tag", locale)
+	}
+	want, _ := tag.Base()
+	got, _ := resolveLocale(locale).Base()
+	return want == got, nil
+}
@@ -0,0 +1,240 @@
+// Package main provides tests for localized notifications.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestCatalogsComplete checks that every catalog translates every English
+// message with the same number of format verbs.
+func TestCatalogsComplete(t *testing.T) {
+	read := func(t *testing.T, name string) localeCatalog {
+		t.Helper()
+		data, err := fs.ReadFile(localeFiles, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lc localeCatalog
+		if err := json.Unmarshal(data, &lc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		return lc
+	}
+	verbs := func(raw json.RawMessage) int {
+		return strings.Count(string(raw), "%")
+	}
+
+	en := read(t, "locales/en.json")
+	files, _ := fs.Glob(localeFiles, "locales/*.json")
+	if len(files) < 6 {
+		t.Errorf("expected at least 6 catalogs, got %v", files)
+	}
+
+	for _, name := range files {
+		lc := read(t, name)
+		for key := range en.Messages {
+			raw, ok := lc.Messages[key]
+			if !ok {
+				t.Errorf("%s: missing message %q", name, key)
+				continue
+			}
+			// Plural objects repeat the verb once per form.
+			if !strings.HasPrefix(string(raw), "{") && verbs(raw) != strings.Count(key, "%") {
+				t.Errorf("%s: message %q has mismatched format verbs: %s", name, key, raw)
+			}
+		}
+		for key := range lc.Messages {
+			if _, ok := en.Messages[key]; !ok {
+				t.Errorf("%s: message %q is not in en.json", name, key)
+			}
+		}
+	}
+}
+
+// TestChangeSummaryPlurals tests plural rules per locale.
+func TestChangeSummaryPlurals(t *testing.T) {
+	changes := &plugin.CategorizedChanges{
+		Features: []plugin.ConventionalCommit{{Description: "a"}},
+		Breaking: []plugin.ConventionalCommit{{Description: "b"}, {Description: "c"}},
+	}
+
+	tests := map[string]string{
+		"en": "1 feature, 0 fixes, 2 breaking changes",
+		"de": "1 neue Funktion, 0 Fehlerbehebungen, 2 inkompatible Änderungen",
+		// French treats 0 as singular.
+		"fr": "1 fonctionnalité, 0 correction, 2 changements incompatibles",
+		"es": "1 funcionalidad, 0 correcciones, 2 cambios incompatibles",
+		// So does Portuguese (CLDR "pt" follows Brazilian usage).
+		"pt": "1 funcionalidade, 0 correção, 2 alterações incompatíveis",
+		"ja": "新機能 1 件, 修正 0 件, 破壊的変更 2 件",
+	}
+	for locale, want := range tests {
+		if got := changeSummary(newPrinter(locale), changes); got != want {
+			t.Errorf("%s: expected %q, got %q", locale, want, got)
+		}
+	}
+}
+
+// TestResolveLocale tests matching configured locales to catalogs.
+func TestResolveLocale(t *testing.T) {
+	tests := map[string]language.Tag{
+		"":        language.English,
+		"de":      language.German,
+		"de-AT":   language.German,
+		"pt-BR":   language.Portuguese,
+		"ja-JP":   language.Japanese,
+		"sw":      language.English,
+		"invalid": language.English,
+	}
+	for locale, want := range tests {
+		if got := resolveLocale(locale); got != want {
+			t.Errorf("resolveLocale(%q) = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+// TestLoadCatalogsErrors tests that malformed catalogs are rejected.
+func TestLoadCatalogsErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad json":       `{`,
+		"bad language":   `{"language": "!!", "messages": {}}`,
+		"missing other":  `{"language": "de", "messages": {"%d fixes": {"one": "%d Fix"}}}`,
+		"unknown plural": `{"language": "de", "messages": {"%d fixes": {"several": "%d", "other": "%d"}}}`,
+		"bad value":      `{"language": "de", "messages": {"Tag": 1}}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			fsys := fstest.MapFS{"locales/de.json": {Data: []byte(content)}}
+			if _, _, err := loadCatalogs(fsys); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// TestLocalizedNotifications tests per-destination locales end to end.
+func TestLocalizedNotifications(t *testing.T) {
+	calls, _ := recordingSlackAPI(t, nil)
+
+	resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookOnSuccess,
+		Config: map[string]any{
+			"bot_token": "xoxb-1-2-3",
+			"channel":   "#releases",
+			"state_dir": t.TempDir(),
+			"destinations": []any{
+				map[string]any{"channel": "#releases-de", "locale": "de"},
+				map[string]any{"channel": "#releases-ja", "locale": "ja"},
+			},
+		},
+		Context: plugin.ReleaseContext{Version: "2.0.0", ReleaseType: "major", Branch: "main"},
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("expected success, got %v / %+v", err, resp)
+	}
+
+	titles := map[string]string{}
+	fields := map[string]string{}
+	for _, c := range *calls {
+		if c.Method != "chat.postMessage" {
+			continue
+		}
+		channel, _ := c.Body["channel"].(string)
+		att := c.Body["attachments"].([]any)[0].(map[string]any)
+		titles[channel], _ = att["title"].(string)
+		for _, f := range att["fields"].([]any) {
+			field := f.(map[string]any)
+			if field["value"] == "Major" || field["value"] == "メジャー" {
+				fields[channel] = field["title"].(string)
+			}
+		}
+	}
+
+	want := map[string]string{
+		"#releases":    ":tada: Major Release 2.0.0 Published!",
+		"#releases-de": ":tada: Major-Release 2.0.0 veröffentlicht!",
+		"#releases-ja": ":tada: メジャーリリース 2.0.0 を公開しました！",
+	}
+	for channel, title := range want {
+		if titles[channel] != title {
+			t.Errorf("%s: expected title %q, got %q", channel, title, titles[channel])
+		}
+	}
+	if fields["#releases-de"] != "Release-Typ" || fields["#releases-ja"] != "リリース種別" {
+		t.Errorf("expected localized field names, got %v", fields)
+	}
+}
+
+// TestLocalizedTemplates tests the t template function and default templates.
+func TestLocalizedTemplates(t *testing.T) {
+	releaseCtx := plugin.ReleaseContext{Version: "1.1.0", TagName: "v1.1.0", PreviousVersion: "1.0.0", ReleaseType: "minor"}
+
+	got, err := renderTemplate(newPrinter("fr"), "plan_template", defaultPlanTemplate, releaseCtx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := ":memo: *Version 1.1.0 planifiée* (mineure), après 1.0.0"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	// Translations may reorder arguments.
+	if got := translate(newPrinter("fr"), "*%s* release *%s* is ready to publish.", "acme/app", "1.1.0"); got != "La version *1.1.0* de *acme/app* est prête à être publiée." {
+		t.Errorf("unexpected reordered translation %q", got)
+	}
+
+	got, _ = renderTemplate(newPrinter("es"), "custom", `{{t "Approve"}} {{t "not in the catalog"}}`, releaseCtx)
+	if got != "Aprobar not in the catalog" {
+		t.Errorf("unexpected custom template output %q", got)
+	}
+}
+
+// TestReleaseTypeName tests that release types map to fixed catalog entries.
+func TestReleaseTypeName(t *testing.T) {
+	tests := map[string]string{
+		"minor":     "mineure",
+		"hotfix %s": "hotfix %s",
+		"100%":      "100%",
+		"":          "",
+	}
+	for releaseType, want := range tests {
+		if got := releaseTypeName(newPrinter("fr"), releaseType); got != want {
+			t.Errorf("releaseTypeName(%q) = %q, want %q", releaseType, got, want)
+		}
+	}
+
+	got, err := renderTemplate(newPrinter("fr"), "plan_template", defaultPlanTemplate, plugin.ReleaseContext{Version: "1.1.0", ReleaseType: "50% rollout"})
+	if err != nil || got != ":memo: *Version 1.1.0 planifiée* (50% rollout)" {
+		t.Errorf("expected the release type verbatim, got %q, %v", got, err)
+	}
+}
+
+// TestLocalizedTimestamps tests per-locale timestamp layouts.
+func TestLocalizedTimestamps(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 7, 0, 0, time.UTC)
+	tests := map[string]string{
+		"en": "Mar 5, 2024 14:07 UTC",
+		"de": "05.03.2024 14:07 UTC",
+		"fr": "05/03/2024 14:07 UTC",
+		"ja": "2024/03/05 14:07 UTC",
+	}
+	for locale, want := range tests {
+		if got := formatTimestamp(newPrinter(locale), at); got != want {
+			t.Errorf("%s: expected %q, got %q", locale, want, got)
+		}
+	}
+
+	d := approvalDecision{UserID: "U1", Reason: "zu früh"}
+	if got := decisionText(newPrinter("de"), d, at); got != ":no_entry: Release abgelehnt von <@U1> am 05.03.2024 14:07 UTC: zu früh" {
+		t.Errorf("unexpected decision text %q", got)
+	}
+}
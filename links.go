@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/text/message"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Asset is a downloadable file of a release, such as a published binary.
+type Asset struct {
+	// Name is the link text; empty uses the URL.
+	Name string `json:"name,omitempty"`
+	// URL is a Go template rendering the download link.
+	URL string `json:"url"`
+}
+
+// assetLink is a rendered asset, as available to templates.
+type assetLink struct {
+	Name string
+	URL  string
+}
+
+// AttachmentAction is a link button of a legacy attachment.
+type AttachmentAction struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// parseAssets parses the assets list. Entries that are not objects are skipped.
+func parseAssets(raw any) []Asset {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]Asset, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		parser := helpers.NewConfigParser(m)
+		out = append(out, Asset{
+			Name: parser.GetString("name", "", ""),
+			URL:  parser.GetString("url", "", ""),
+		})
+	}
+	return out
+}
+
+// parseVars parses the vars map, keeping string values.
+func parseVars(raw map[string]any) map[string]string {
+	if len(raw) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
+
+// linkData builds unescaped template data for a release context with the
+// configured vars, release page, assets and downloads link. Each link may
+// use the data before it, so assets can point below .ReleaseURL. Links that
+// render empty, for example from a missing var, are left out; an empty
+// release_url keeps the release page derived from the repository.
+func (c *Config) linkData(releaseCtx plugin.ReleaseContext) (templateData, error) {
+	pr := c.printer()
+	data := newPlainTemplateData(pr, releaseCtx)
+	if len(c.Vars) > 0 {
+		data.Vars = make(map[string]string, len(c.Vars))
+		for k, v := range c.Vars {
+			data.Vars[k] = v
+		}
+	}
+
+	if c.ReleaseURL != "" {
+		link, err := executeTemplate(pr, "release_url", c.ReleaseURL, data)
+		if err != nil {
+			return templateData{}, err
+		}
+		if link != "" {
+			data.ReleaseURL = link
+		}
+	}
+	for i, a := range c.Assets {
+		link, err := executeTemplate(pr, fmt.Sprintf("assets[%d].url", i), a.URL, data)
+		if err != nil {
+			return templateData{}, err
+		}
+		if link == "" {
+			continue
+		}
+		name := a.Name
+		if name == "" {
+			name = link
+		}
+		data.Assets = append(data.Assets, assetLink{Name: name, URL: link})
+	}
+	if c.DownloadsURL != "" {
+		link, err := executeTemplate(pr, "downloads_url", c.DownloadsURL, data)
+		if err != nil {
+			return templateData{}, err
+		}
+		data.DownloadsURL = link
+	}
+	return data, nil
+}
+
+// templateData builds the template data for mrkdwn messages: the links are
+// rendered from unescaped values by linkData, then escaped with the vars.
+func (c *Config) templateData(releaseCtx plugin.ReleaseContext) (templateData, error) {
+	links, err := c.linkData(releaseCtx)
+	if err != nil {
+		return templateData{}, err
+	}
+	data := newTemplateData(c.printer(), releaseCtx)
+	if len(links.Vars) > 0 {
+		data.Vars = make(map[string]string, len(links.Vars))
+		for k, v := range links.Vars {
+			data.Vars[k] = mrkdwnEscaper.Replace(v)
+		}
+	}
+	data.ReleaseURL = mrkdwnEscaper.Replace(links.ReleaseURL)
+	data.DownloadsURL = mrkdwnEscaper.Replace(links.DownloadsURL)
+	for _, a := range links.Assets {
+		data.Assets = append(data.Assets, assetLink{Name: mrkdwnEscaper.Replace(a.Name), URL: mrkdwnEscaper.Replace(a.URL)})
+	}
+	return data, nil
+}
+
+// renderTemplate renders a message template with the target's language,
+// vars and links.
+func (c *Config) renderTemplate(name, text string, releaseCtx plugin.ReleaseContext) (string, error) {
+	data, err := c.templateData(releaseCtx)
+	if err != nil {
+		return "", err
+	}
+	return executeTemplate(c.printer(), name, text, data)
+}
+
+// renderPlainTemplate renders a template whose output is not mrkdwn, such as
+// a channel topic or bookmark, from unescaped data.
+func (c *Config) renderPlainTemplate(name, text string, releaseCtx plugin.ReleaseContext) (string, error) {
+	data, err := c.linkData(releaseCtx)
+	if err != nil {
+		return "", err
+	}
+	return executeTemplate(c.printer(), name, text, data)
+}
+
+// releaseButtons returns the "Release page" and "Downloads" link buttons.
+// Downloads links to downloads_url, or to the only asset. Buttons whose link
+// is empty or not an absolute http(s) URL are left out, since Slack would
+// reject the whole message. data must come from linkData, unescaped.
+func releaseButtons(pr *message.Printer, data templateData) []AttachmentAction {
+	downloads := data.DownloadsURL
+	if downloads == "" && len(data.Assets) == 1 {
+		downloads = data.Assets[0].URL
+	}
+	var actions []AttachmentAction
+	for _, b := range []struct{ name, text, url string }{
+		{"release_page", translate(pr, "Release page"), data.ReleaseURL},
+		{"downloads", translate(pr, "Downloads"), downloads},
+	} {
+		if b.url == "" || checkURL(b.url) != nil {
+			continue
+		}
+		actions = append(actions, AttachmentAction{Type: "button", Name: b.name, Text: b.text, URL: b.url})
+	}
+	return actions
+}
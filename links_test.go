@@ -0,0 +1,167 @@
+// Package main provides tests for vars and release links.
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/plugin-slack/slacktest"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestTemplateVarsAndLinks tests .Vars, release_url, assets and downloads_url in templates.
+func TestTemplateVarsAndLinks(t *testing.T) {
+	releaseCtx := plugin.ReleaseContext{
+		Version:       "1.4.0",
+		TagName:       "v1.4.0",
+		RepositoryURL: "https://github.com/acme/app",
+		Environment:   map[string]string{"CDN": "https://cdn.example.com"},
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		text string
+		want string
+	}{
+		{
+			name: "vars",
+			cfg:  Config{Vars: map[string]string{"jira": "REL-12 & REL-13"}},
+			text: "{{.Vars.jira}}",
+			want: "REL-12 &amp; REL-13",
+		},
+		{name: "missing var", text: "[{{.Vars.jira}}]", want: "[]"},
+		{
+			name: "release URL",
+			cfg:  Config{Vars: map[string]string{"release_url": "https://github.com/acme/app/releases/1"}, ReleaseURL: "{{.Vars.release_url}}"},
+			text: "{{.ReleaseURL}}",
+			want: "https://github.com/acme/app/releases/1",
+		},
+		{
+			name: "missing release URL keeps the derived one",
+			cfg:  Config{ReleaseURL: "{{.Vars.release_url}}"},
+			text: "{{.ReleaseURL}}",
+			want: "https://github.com/acme/app/releases/tag/v1.4.0",
+		},
+		{
+			name: "assets",
+			cfg: Config{Assets: []Asset{
+				{Name: "Linux", URL: "{{.Environment.CDN}}/app-{{.Version}}-linux.tar.gz"},
+				{URL: "{{.Vars.missing}}"},
+				{URL: "{{.ReleaseURL}}/app.zip"},
+			}},
+			text: "{{range .Assets}}{{.Name}}={{.URL}};{{end}}",
+			want: "Linux=https://cdn.example.com/app-1.4.0-linux.tar.gz;https://github.com/acme/app/releases/tag/v1.4.0/app.zip=https://github.com/acme/app/releases/tag/v1.4.0/app.zip;",
+		},
+		{
+			name: "downloads URL",
+			cfg:  Config{DownloadsURL: "{{.Environment.CDN}}/{{.Version}}/"},
+			text: "{{.DownloadsURL}}",
+			want: "https://cdn.example.com/1.4.0/",
+		},
+		{
+			name: "links are escaped once",
+			cfg: Config{
+				Vars:         map[string]string{"cdn": "https://cdn.example.com/dl?v=1.4.0"},
+				DownloadsURL: "{{.Vars.cdn}}&arch=amd64",
+				Assets:       []Asset{{Name: "Linux & macOS", URL: "{{.Vars.cdn}}&os=unix"}},
+			},
+			text: "{{.DownloadsURL}} {{range .Assets}}{{.Name}}={{.URL}}{{end}}",
+			want: "https://cdn.example.com/dl?v=1.4.0&amp;arch=amd64 Linux &amp; macOS=https://cdn.example.com/dl?v=1.4.0&amp;os=unix",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.renderTemplate("test_template", tt.text, releaseCtx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReleaseButtons tests the Release page and Downloads buttons in both formats.
+func TestReleaseButtons(t *testing.T) {
+	p, server := newFakeSlack(t)
+	releaseCtx := plugin.ReleaseContext{Version: "1.4.0", TagName: "v1.4.0", ReleaseType: "minor"}
+	send := func(config map[string]any) map[string]any {
+		t.Helper()
+		config["webhook"] = testWebhookURL
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{Hook: plugin.HookPostPublish, Config: config, Context: releaseCtx})
+		if err != nil || !resp.Success {
+			t.Fatalf("expected success, got %v / %+v", err, resp)
+		}
+		calls := server.Calls(slacktest.Webhook)
+		return calls[len(calls)-1].Body
+	}
+
+	body := send(map[string]any{
+		"release_buttons": true,
+		"vars":            map[string]any{"release_url": "https://github.com/acme/app/releases/tag/v1.4.0?a=1&b=2"},
+		"release_url":     "{{.Vars.release_url}}",
+		"assets":          []any{map[string]any{"name": "app.zip", "url": "https://cdn.example.com/app.zip?sig=a&amp;b"}},
+		"locale":          "de",
+	})
+	att := body["attachments"].([]any)[0].(map[string]any)
+	actions, _ := att["actions"].([]any)
+	if len(actions) != 2 {
+		t.Fatalf("expected two buttons, got %v", att)
+	}
+	page, downloads := actions[0].(map[string]any), actions[1].(map[string]any)
+	if page["text"] != "Release-Seite" || page["url"] != "https://github.com/acme/app/releases/tag/v1.4.0?a=1&b=2" {
+		t.Errorf("unexpected release page button %v", page)
+	}
+	if downloads["text"] != "Downloads" || downloads["url"] != "https://cdn.example.com/app.zip?sig=a&amp;b" {
+		t.Errorf("expected the only asset as downloads, got %v", downloads)
+	}
+
+	body = send(map[string]any{
+		"release_buttons": true,
+		"format":          "blocks",
+		"release_url":     "{{.Vars.release_url}}",
+		"downloads_url":   "not a url",
+	})
+	blocks := body["attachments"].([]any)[0].(map[string]any)["blocks"].([]any)
+	var buttons []any
+	for _, b := range blocks {
+		if block := b.(map[string]any); block["type"] == "actions" {
+			buttons = block["elements"].([]any)
+		}
+	}
+	if len(buttons) != 0 {
+		t.Errorf("expected missing and invalid links to leave the buttons out, got %v", buttons)
+	}
+
+	releaseCtx.RepositoryURL = "https://github.com/acme/app"
+	body = send(map[string]any{"release_buttons": true, "format": "blocks"})
+	if !strings.Contains(strings.Join(blockButtonURLs(body), " "), "https://github.com/acme/app/releases/tag/v1.4.0") {
+		t.Errorf("expected the derived release page button, got %v", body)
+	}
+
+	body = send(map[string]any{"release_url": "https://example.com/release"})
+	if _, ok := body["attachments"].([]any)[0].(map[string]any)["actions"]; ok {
+		t.Error("expected no buttons unless release_buttons is set")
+	}
+}
+
+// blockButtonURLs returns the URLs of the buttons in a Block Kit notification.
+func blockButtonURLs(body map[string]any) []string {
+	var urls []string
+	for _, att := range body["attachments"].([]any) {
+		blocks, _ := att.(map[string]any)["blocks"].([]any)
+		for _, b := range blocks {
+			elements, _ := b.(map[string]any)["elements"].([]any)
+			for _, e := range elements {
+				if u, ok := e.(map[string]any)["url"].(string); ok {
+					urls = append(urls, u)
+				}
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// correlationIDOutput is the output carrying the ID that tags the log
+// entries of one hook execution.
+const correlationIDOutput = "correlation_id"
+
+// newCorrelationID returns a random identifier for one hook execution.
+func newCorrelationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// redactingWriter masks secrets in each log entry before writing it.
+type redactingWriter struct {
+	w io.Writer
+	r *redactor
+}
+
+func (rw redactingWriter) Write(b []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.r.String(string(b))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// requestLogger creates the logger for one hook execution. Entries are JSON,
+// which go-plugin forwards to Relicta's log with their level and fields, and
+// secrets are masked by r.
+func (p *SlackPlugin) requestLogger(cfg *Config, r *redactor, correlationID string) hclog.Logger {
+	if cfg.LogLevel == "off" {
+		return hclog.NewNullLogger()
+	}
+	out := p.logOutput
+	if out == nil {
+		out = os.Stderr
+	}
+	return hclog.New(&hclog.LoggerOptions{
+		Name:       "slack",
+		Level:      hclog.LevelFromString(cfg.LogLevel),
+		Output:     redactingWriter{w: out, r: r},
+		JSONFormat: true,
+	}).With(correlationIDOutput, correlationID)
+}
+
+// logger returns the logger of the hook execution running in ctx.
+func logger(ctx context.Context) hclog.Logger {
+	return hclog.FromContext(ctx)
+}
+
+// logRequest logs one HTTP request to Slack: a debug entry when it
+// succeeded, a warning otherwise. destination is a webhook URL, which the
+// logger redacts, or a Web API method; code is Slack's error code, if any.
+func logRequest(ctx context.Context, destination string, attempt, size int, start time.Time, status int, code string, err error) {
+	args := []any{
+		"destination", destination,
+		"attempt", attempt,
+		"payload_bytes", size,
+		"latency_ms", time.Since(start).Milliseconds(),
+	}
+	if status != 0 {
+		args = append(args, "status", status)
+	}
+	if code != "" {
+		args = append(args, "slack_error", code)
+	}
+	if err != nil {
+		logger(ctx).Warn("slack request failed", append(args, "error", err)...)
+		return
+	}
+	logger(ctx).Debug("slack request", args...)
+}
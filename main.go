@@ -2,9 +2,17 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
 )
 
 func main() {
-	plugin.Serve(&SlackPlugin{})
+	// Relicta starts plugins with the handshake cookie and no arguments.
+	if len(os.Args) < 2 || plugin.IsPlugin() {
+		plugin.Serve(NewSlackPlugin())
+		return
+	}
+	os.Exit(runCLI(context.Background(), NewSlackPlugin(), os.Args[1:], os.Stdout, os.Stderr))
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+	"golang.org/x/text/message"
+)
+
+const (
+	// metricsWeek is the window of the "releases this week" count.
+	metricsWeek = 7 * 24 * time.Hour
+	// metricsWindow is the window of deployment frequency and change failure rate.
+	metricsWindow = 28 * 24 * time.Hour
+)
+
+// releaseMetrics describes a release in the context of the release history.
+// Counts include the release itself.
+type releaseMetrics struct {
+	// ReleasesThisWeek counts successful releases in the last seven days.
+	ReleasesThisWeek int
+	// DaysSinceLastOfType is the number of days since the previous successful
+	// release of the same type, or -1 if there was none.
+	DaysSinceLastOfType int
+	// ReleaseType is the lower-cased release type.
+	ReleaseType string
+	// Commits counts the categorized commits since PreviousVersion, or -1 if unknown.
+	Commits int
+	// PreviousVersion is the version the commits are counted from.
+	PreviousVersion string
+	// DeploymentsPerWeek is the average number of successful releases per
+	// week over the last 28 days.
+	DeploymentsPerWeek float64
+	// ChangeFailureRate is the share of failed releases over the last 28 days.
+	ChangeFailureRate float64
+}
+
+// computeMetrics derives metrics for a successful release at now. Earlier
+// successful entries for the same version are ignored, so the release is
+// counted once whether or not it was already recorded.
+func computeMetrics(history []historyEntry, releaseCtx plugin.ReleaseContext, now time.Time) releaseMetrics {
+	m := releaseMetrics{
+		ReleasesThisWeek:    1,
+		DaysSinceLastOfType: -1,
+		ReleaseType:         strings.ToLower(releaseCtx.ReleaseType),
+		Commits:             -1,
+		PreviousVersion:     releaseCtx.PreviousVersion,
+	}
+	if releaseCtx.Changes != nil {
+		m.Commits = newHistoryEntry(releaseCtx, outcomeSuccess, now).Commits
+	}
+
+	successes, failures := 1, 0
+	var lastOfType time.Time
+	for _, e := range history {
+		if e.Time.After(now) {
+			continue
+		}
+		if e.Outcome == outcomeSuccess && e.Version == releaseCtx.Version {
+			continue
+		}
+		age := now.Sub(e.Time)
+
+		if e.Outcome == outcomeSuccess {
+			if age < metricsWeek {
+				m.ReleasesThisWeek++
+			}
+			if m.ReleaseType != "" && strings.EqualFold(e.ReleaseType, m.ReleaseType) && e.Time.After(lastOfType) {
+				lastOfType = e.Time
+			}
+		}
+		if age < metricsWindow {
+			switch e.Outcome {
+			case outcomeSuccess:
+				successes++
+			case outcomeFailure:
+				failures++
+			}
+		}
+	}
+
+	if !lastOfType.IsZero() {
+		m.DaysSinceLastOfType = int(now.Sub(lastOfType) / (24 * time.Hour))
+	}
+	m.DeploymentsPerWeek = float64(successes) / (float64(metricsWindow) / float64(metricsWeek))
+	m.ChangeFailureRate = float64(failures) / float64(successes+failures)
+	return m
+}
+
+// loadMetrics computes metrics for a release from the recorded history.
+func loadMetrics(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, now time.Time) (releaseMetrics, error) {
+	// Days since the last release of the same type may reach back further
+	// than the metrics window, so the whole history is read.
+	history, err := readHistory(ctx, cfg.StateDir, time.Time{}, now.Add(time.Second))
+	if err != nil {
+		return releaseMetrics{}, err
+	}
+	return computeMetrics(history, releaseCtx, now), nil
+}
+
+// lines returns the metrics as localized context lines.
+func (m releaseMetrics) lines(pr *message.Printer) []string {
+	lines := []string{translate(pr, "%d releases this week", m.ReleasesThisWeek)}
+	if m.DaysSinceLastOfType >= 0 {
+		lines = append(lines, translate(pr, "%d days since the last %s release", m.DaysSinceLastOfType, releaseTypeName(pr, m.ReleaseType)))
+	}
+	if m.Commits >= 0 && m.PreviousVersion != "" {
+		lines = append(lines, translate(pr, "%d commits since %s", m.Commits, m.PreviousVersion))
+	}
+	lines = append(lines,
+		translate(pr, "Deployment frequency: %.1f per week", m.DeploymentsPerWeek),
+		translate(pr, "Change failure rate: %.0f%%", m.ChangeFailureRate*100),
+	)
+	return lines
+}
+
+// outputs returns the metrics for ExecuteResponse.Outputs. Unknown values are omitted.
+func (m releaseMetrics) outputs() map[string]any {
+	out := map[string]any{
+		"releases_this_week":            m.ReleasesThisWeek,
+		"deployment_frequency_per_week": math.Round(m.DeploymentsPerWeek*100) / 100,
+		"change_failure_rate":           math.Round(m.ChangeFailureRate*1000) / 1000,
+	}
+	if m.DaysSinceLastOfType >= 0 {
+		out["days_since_last_of_type"] = m.DaysSinceLastOfType
+	}
+	if m.Commits >= 0 {
+		out["commits"] = m.Commits
+	}
+	return out
+}
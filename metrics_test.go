@@ -0,0 +1,112 @@
+// Package main provides tests for release metrics.
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestComputeMetrics tests cadence, lead time and DORA metrics.
+func TestComputeMetrics(t *testing.T) {
+	now := time.Date(2024, 3, 29, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+	history := []historyEntry{
+		{Time: daysAgo(40), Version: "1.0.0", ReleaseType: "minor", Outcome: outcomeSuccess},
+		{Time: daysAgo(12), Version: "1.1.0", ReleaseType: "minor", Outcome: outcomeSuccess},
+		{Time: daysAgo(5), Version: "1.1.1", ReleaseType: "patch", Outcome: outcomeSuccess},
+		{Time: daysAgo(2), Version: "1.2.0", ReleaseType: "minor", Outcome: outcomeFailure},
+		// The release itself, already recorded by on-success.
+		{Time: now, Version: "1.2.0", ReleaseType: "minor", Outcome: outcomeSuccess},
+	}
+	releaseCtx := plugin.ReleaseContext{
+		Version:         "1.2.0",
+		PreviousVersion: "1.1.1",
+		ReleaseType:     "minor",
+		Changes: &plugin.CategorizedChanges{
+			Features: make([]plugin.ConventionalCommit, 30),
+			Other:    make([]plugin.ConventionalCommit, 12),
+		},
+	}
+
+	m := computeMetrics(history, releaseCtx, now)
+	want := releaseMetrics{
+		ReleasesThisWeek:    2,
+		DaysSinceLastOfType: 12,
+		ReleaseType:         "minor",
+		Commits:             42,
+		PreviousVersion:     "1.1.1",
+		DeploymentsPerWeek:  0.75,
+		ChangeFailureRate:   0.25,
+	}
+	if m != want {
+		t.Fatalf("expected %+v, got %+v", want, m)
+	}
+
+	wantLines := []string{
+		"2 releases this week",
+		"12 days since the last minor release",
+		"42 commits since 1.1.1",
+		"Deployment frequency: 0.8 per week",
+		"Change failure rate: 25%",
+	}
+	if got := m.lines(newPrinter("en")); !reflect.DeepEqual(got, wantLines) {
+		t.Errorf("expected %q, got %q", wantLines, got)
+	}
+	if got := m.lines(newPrinter("de"))[3]; got != "Deployment-Häufigkeit: 0,8 pro Woche" {
+		t.Errorf("expected localized decimals, got %q", got)
+	}
+	if got := m.lines(newPrinter("ja"))[1]; got != "前回のマイナーリリースから 12 日" {
+		t.Errorf("expected reordered arguments, got %q", got)
+	}
+
+	// Without history only the release itself is known.
+	m = computeMetrics(nil, plugin.ReleaseContext{Version: "0.1.0", ReleaseType: "minor"}, now)
+	if m.ReleasesThisWeek != 1 || m.DaysSinceLastOfType != -1 || m.Commits != -1 || m.ChangeFailureRate != 0 {
+		t.Errorf("unexpected metrics without history %+v", m)
+	}
+	if _, ok := m.outputs()["commits"]; ok {
+		t.Error("expected unknown commits to be omitted from outputs")
+	}
+}
+
+// TestMetricsNotification tests metrics in the message and outputs end to end.
+func TestMetricsNotification(t *testing.T) {
+	calls, _ := recordingSlackAPI(t, nil)
+	dir := t.TempDir()
+	if err := appendHistory(dir, historyEntry{Time: time.Now().Add(-time.Hour), Version: "1.0.0", ReleaseType: "minor", Outcome: outcomeSuccess}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+		Hook: plugin.HookOnSuccess,
+		Config: map[string]any{
+			"bot_token": "xoxb-1-2-3", "channel": "#releases", "state_dir": dir,
+			"history": true, "metrics": true,
+		},
+		Context: plugin.ReleaseContext{Version: "1.1.0", ReleaseType: "minor"},
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("expected success, got %v / %+v", err, resp)
+	}
+
+	metrics, _ := resp.Outputs["metrics"].(map[string]any)
+	if metrics["releases_this_week"] != 2 || metrics["days_since_last_of_type"] != 0 {
+		t.Errorf("unexpected metrics outputs %v", resp.Outputs)
+	}
+
+	for _, c := range *calls {
+		if c.Method != "chat.postMessage" {
+			continue
+		}
+		fields := c.Body["attachments"].([]any)[0].(map[string]any)["fields"].([]any)
+		last := fields[len(fields)-1].(map[string]any)
+		if last["title"] != "Metrics" || !strings.HasPrefix(last["value"].(string), "2 releases this week\n0 days since the last minor release") {
+			t.Errorf("unexpected metrics field %v", last)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// Option configures a SlackPlugin.
+type Option func(*SlackPlugin)
+
+// NewSlackPlugin creates the plugin. Without options it talks to Slack with
+// the hardened default client and uses the system clock. Every plugin paces
+// its requests with its own rate limiter.
+func NewSlackPlugin(opts ...Option) *SlackPlugin {
+	p := &SlackPlugin{limiter: newRateLimiter()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// WithHTTPClient sends all requests to Slack with client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *SlackPlugin) {
+		p.client = client
+	}
+}
+
+// WithClock makes the plugin read the current time from now, which stamps
+// messages and drives scheduling, history and digests.
+func WithClock(now func() time.Time) Option {
+	return func(p *SlackPlugin) {
+		p.clock = now
+	}
+}
+
+// WithBaseURL sends Web API calls and Slack webhook requests to baseURL,
+// e.g. a slacktest server, instead of slack.com and hooks.slack.com.
+func WithBaseURL(baseURL string) Option {
+	return func(p *SlackPlugin) {
+		p.baseURL = baseURL
+	}
+}
+
+// WithStateDir sets the state directory used when the configuration does
+// not set state_dir.
+func WithStateDir(dir string) Option {
+	return func(p *SlackPlugin) {
+		p.stateDir = dir
+	}
+}
+
+// WithLogOutput writes the plugin's JSON log entries to w instead of stderr.
+func WithLogOutput(w io.Writer) Option {
+	return func(p *SlackPlugin) {
+		p.logOutput = w
+	}
+}
+
+// WithoutRateLimits sends requests as fast as they come, e.g. to a slacktest
+// server. Requests answered with 429 still wait for Retry-After.
+func WithoutRateLimits() Option {
+	return func(p *SlackPlugin) {
+		p.limiter = newRateLimiter()
+		p.limiter.unlimited = true
+	}
+}
+
+// now returns the current time from the configured clock.
+func (p *SlackPlugin) now() time.Time {
+	if p.clock != nil {
+		return p.clock()
+	}
+	return time.Now()
+}
+
+// defaultStateDir returns the state directory used when state_dir is not set.
+func (p *SlackPlugin) defaultStateDir() string {
+	if p.stateDir != "" {
+		return p.stateDir
+	}
+	return ".relicta/slack"
+}
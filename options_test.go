@@ -0,0 +1,85 @@
+// Package main provides tests for plugin options.
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/plugin-slack/slacktest"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestPluginOptions tests that the clock, state directory, client and base
+// URL are used throughout a release.
+func TestPluginOptions(t *testing.T) {
+	server := slacktest.NewServer()
+	defer server.Close()
+
+	now := time.Date(2024, 3, 5, 14, 7, 0, 0, time.UTC)
+	stateDir := filepath.Join(t.TempDir(), "state")
+	p := NewSlackPlugin(
+		WithHTTPClient(server.Client()),
+		WithBaseURL(server.URL),
+		WithClock(func() time.Time { return now }),
+		WithStateDir(stateDir),
+	)
+
+	config := map[string]any{"webhook": testWebhookURL, "history": true, "format": formatBlocks}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookOnSuccess,
+		Config:  config,
+		Context: plugin.ReleaseContext{Version: "1.2.0", ReleaseType: "minor"},
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("expected success, got %v / %+v", err, resp)
+	}
+
+	calls := server.Calls(slacktest.Webhook)
+	if len(calls) != 1 {
+		t.Fatalf("expected one webhook request, got %d", len(calls))
+	}
+	if !strings.Contains(string(calls[0].Raw), "!date^1709647620^") {
+		t.Errorf("expected the message to be stamped with the clock, got %s", calls[0].Raw)
+	}
+
+	history, err := readHistory(context.Background(), stateDir, time.Time{}, now.Add(time.Second))
+	if err != nil || len(history) != 1 || !history[0].Time.Equal(now) {
+		t.Errorf("expected the release recorded at the clock's time in the state directory, got %v / %v", history, err)
+	}
+
+	// The digest window ends at the clock's time.
+	now = now.Add(24 * time.Hour)
+	config["mode"] = modeDigest
+	resp, _ = p.Execute(context.Background(), plugin.ExecuteRequest{Hook: digestHook, Config: config, DryRun: true})
+	if resp.Outputs["releases"] != 1 || resp.Outputs["since"] != "2024-02-28T14:07:00Z" {
+		t.Errorf("unexpected digest outputs %v", resp.Outputs)
+	}
+
+	if _, err := os.Stat(filepath.Join(".relicta", "slack")); err == nil {
+		t.Error("expected nothing to be written to the default state directory")
+	}
+}
+
+// TestNewSlackPluginDefaults tests the production defaults.
+func TestNewSlackPluginDefaults(t *testing.T) {
+	p := NewSlackPlugin()
+	if p.httpClient() != defaultHTTPClient {
+		t.Error("expected the hardened default client")
+	}
+	if p.apiURL("auth.test") != "https://slack.com/api/auth.test" {
+		t.Errorf("unexpected API URL %s", p.apiURL("auth.test"))
+	}
+	if p.slackURL(testWebhookURL) != testWebhookURL {
+		t.Error("expected webhook URLs to be unchanged")
+	}
+	if p.parseConfig(map[string]any{}).StateDir != ".relicta/slack" {
+		t.Error("expected the default state directory")
+	}
+	if d := time.Since(p.now()); d < 0 || d > time.Minute {
+		t.Errorf("expected the system clock, got %s", p.now())
+	}
+}
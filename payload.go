@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Slack's message and Block Kit limits, checked before sending.
+const (
+	maxBlocks          = 50
+	maxMessageText     = 40000
+	maxHeaderText      = 150
+	maxSectionText     = 3000
+	maxSectionFields   = 10
+	maxFieldText       = 2000
+	maxContextElements = 10
+	maxActionElements  = 25
+	maxButtonText      = 75
+	maxButtonValue     = 2000
+	maxInputLabel      = 2000
+	maxPlaceholder     = 150
+	maxURLLength       = 3000
+	maxIDLength        = 255
+)
+
+// Payload validation modes.
+const (
+	// payloadFix truncates over-long text and lists and repairs text types.
+	payloadFix = "fix"
+	// payloadStrict rejects any payload exceeding a limit.
+	payloadStrict = "strict"
+)
+
+// payloadError lists the problems that keep a payload from being sent.
+type payloadError struct {
+	Problems []string
+}
+
+func (e *payloadError) Error() string {
+	return "invalid Slack payload: " + strings.Join(e.Problems, "; ")
+}
+
+// payloadChecker walks a message, fixing what it may and collecting problems.
+type payloadChecker struct {
+	fix      bool
+	fixes    []string
+	problems []string
+}
+
+// preparePayload checks msg against Slack's limits before it is previewed or
+// sent. In fix mode it returns a repaired copy and describes each repair;
+// problems that cannot be repaired, such as malformed URLs, are always errors.
+func (c *Config) preparePayload(msg SlackMessage) (SlackMessage, []string, error) {
+	pc := &payloadChecker{fix: c.PayloadValidation != payloadStrict}
+	msg = pc.message(msg)
+	if len(pc.problems) > 0 {
+		return msg, nil, &payloadError{Problems: pc.problems}
+	}
+	return msg, pc.fixes, nil
+}
+
+// violation records a limit violation at path; fixed reports whether it
+// has been repaired.
+func (pc *payloadChecker) violation(path, problem string, fixed bool) {
+	if fixed {
+		pc.fixes = append(pc.fixes, path+": "+problem)
+		return
+	}
+	pc.problems = append(pc.problems, path+": "+problem)
+}
+
+// message checks the message, its blocks and its attachments.
+func (pc *payloadChecker) message(msg SlackMessage) SlackMessage {
+	pc.url("icon_url", msg.IconURL)
+	msg.Blocks = pc.blocks("blocks", msg.Blocks)
+
+	hasBlocks := len(msg.Blocks) > 0
+	if len(msg.Attachments) > 0 {
+		msg.Attachments = append([]Attachment(nil), msg.Attachments...)
+	}
+	for i := range msg.Attachments {
+		att := &msg.Attachments[i]
+		path := fmt.Sprintf("attachments[%d]", i)
+		pc.url(path+".title_link", att.TitleLink)
+		pc.url(path+".footer_icon", att.FooterIcon)
+		for j, a := range att.Actions {
+			pc.url(fmt.Sprintf("%s.actions[%d].url", path, j), a.URL)
+		}
+		att.Blocks = pc.blocks(path+".blocks", att.Blocks)
+		hasBlocks = hasBlocks || len(att.Blocks) > 0
+	}
+
+	// Notifications and screen readers show the text when blocks are present.
+	if hasBlocks && strings.TrimSpace(msg.Text) == "" {
+		fallback, _, _ := strings.Cut(plainTextRendering(msg), "\n")
+		fixed := pc.fix && fallback != ""
+		if fixed {
+			msg.Text = fallback
+		}
+		pc.violation("text", "fallback text is required with blocks", fixed)
+	}
+	msg.Text = pc.length("text", msg.Text, maxMessageText)
+	return msg
+}
+
+// blocks checks a list of blocks, returning the possibly repaired copy.
+func (pc *payloadChecker) blocks(path string, blocks []any) []any {
+	if len(blocks) == 0 {
+		return blocks
+	}
+	blocks = append([]any(nil), blocks...)
+	if len(blocks) > maxBlocks {
+		pc.violation(path, fmt.Sprintf("%d blocks exceed the limit of %d", len(blocks), maxBlocks), pc.fix)
+		if pc.fix {
+			blocks = blocks[:maxBlocks]
+		}
+	}
+	for i, b := range blocks {
+		switch block := b.(type) {
+		case Block:
+			blocks[i] = pc.block(fmt.Sprintf("%s[%d]", path, i), block)
+		case *Block:
+			fixed := pc.block(fmt.Sprintf("%s[%d]", path, i), *block)
+			blocks[i] = &fixed
+		}
+	}
+	return blocks
+}
+
+// block checks one block by type. Unknown block types are passed through.
+func (pc *payloadChecker) block(path string, b Block) Block {
+	if len(b.BlockID) > maxIDLength {
+		pc.violation(path+".block_id", fmt.Sprintf("%d characters exceed the limit of %d", len(b.BlockID), maxIDLength), false)
+	}
+
+	switch b.Type {
+	case "header":
+		if b.Text == nil || b.Text.Text == "" {
+			pc.violation(path+".text", "header text is required", false)
+			break
+		}
+		b.Text = pc.text(path+".text", b.Text, true, maxHeaderText)
+
+	case "section":
+		if b.Text == nil && len(b.Fields) == 0 {
+			pc.violation(path, "section needs text or fields", false)
+		}
+		if b.Text != nil {
+			b.Text = pc.text(path+".text", b.Text, false, maxSectionText)
+		}
+		if len(b.Fields) > maxSectionFields {
+			pc.violation(path+".fields", fmt.Sprintf("%d fields exceed the limit of %d", len(b.Fields), maxSectionFields), pc.fix)
+			if pc.fix {
+				b.Fields = b.Fields[:maxSectionFields]
+			}
+		}
+		if len(b.Fields) > 0 {
+			fields := make([]*TextObject, len(b.Fields))
+			for i, f := range b.Fields {
+				fields[i] = pc.text(fmt.Sprintf("%s.fields[%d]", path, i), f, false, maxFieldText)
+			}
+			b.Fields = fields
+		}
+
+	case "context":
+		b.Elements = pc.elements(path, b.Elements, maxContextElements)
+		for i, e := range b.Elements {
+			if t, ok := e.(*TextObject); ok {
+				b.Elements[i] = pc.text(fmt.Sprintf("%s.elements[%d]", path, i), t, false, maxSectionText)
+			}
+		}
+
+	case "actions":
+		b.Elements = pc.elements(path, b.Elements, maxActionElements)
+		for i, e := range b.Elements {
+			if button, ok := e.(ButtonElement); ok {
+				b.Elements[i] = pc.button(fmt.Sprintf("%s.elements[%d]", path, i), button)
+			}
+		}
+
+	case "input":
+		if b.Label == nil {
+			pc.violation(path+".label", "input label is required", false)
+		} else {
+			b.Label = pc.text(path+".label", b.Label, true, maxInputLabel)
+		}
+		switch el := b.Element.(type) {
+		case nil:
+			pc.violation(path+".element", "input element is required", false)
+		case PlainTextInputElement:
+			if el.Placeholder != nil {
+				el.Placeholder = pc.text(path+".element.placeholder", el.Placeholder, true, maxPlaceholder)
+			}
+			b.Element = el
+		}
+	}
+	return b
+}
+
+// elements checks the element count of a context or actions block and
+// returns a copy that may be repaired.
+func (pc *payloadChecker) elements(path string, elements []any, limit int) []any {
+	if len(elements) == 0 {
+		pc.violation(path+".elements", "at least one element is required", false)
+		return elements
+	}
+	elements = append([]any(nil), elements...)
+	if len(elements) > limit {
+		pc.violation(path+".elements", fmt.Sprintf("%d elements exceed the limit of %d", len(elements), limit), pc.fix)
+		if pc.fix {
+			elements = elements[:limit]
+		}
+	}
+	return elements
+}
+
+// button checks a button element.
+func (pc *payloadChecker) button(path string, b ButtonElement) ButtonElement {
+	if b.Text == nil || b.Text.Text == "" {
+		pc.violation(path+".text", "button text is required", false)
+	} else {
+		b.Text = pc.text(path+".text", b.Text, true, maxButtonText)
+	}
+	if b.ActionID == "" || len(b.ActionID) > maxIDLength {
+		pc.violation(path+".action_id", fmt.Sprintf("action_id must have 1 to %d characters", maxIDLength), false)
+	}
+	if len(b.Value) > maxButtonValue {
+		pc.violation(path+".value", fmt.Sprintf("%d characters exceed the limit of %d", len(b.Value), maxButtonValue), false)
+	}
+	if b.Style != "" && b.Style != "primary" && b.Style != "danger" {
+		pc.violation(path+".style", fmt.Sprintf("style %q must be primary or danger", b.Style), false)
+	}
+	pc.url(path+".url", b.URL)
+	return b
+}
+
+// text checks a text object's type and length, returning a repaired copy.
+// plainOnly marks places where Slack only accepts plain_text.
+func (pc *payloadChecker) text(path string, t *TextObject, plainOnly bool, limit int) *TextObject {
+	if t == nil {
+		return nil
+	}
+	fixed := *t
+	switch {
+	case fixed.Type == "plain_text":
+	case fixed.Type == "mrkdwn" && plainOnly:
+		pc.violation(path+".type", "must be plain_text", pc.fix)
+		if pc.fix {
+			fixed.Type = "plain_text"
+		}
+	case fixed.Type == "mrkdwn":
+		if fixed.Emoji {
+			// emoji is a plain_text option that Slack rejects on mrkdwn.
+			pc.violation(path+".emoji", "emoji is only allowed on plain_text", pc.fix)
+			if pc.fix {
+				fixed.Emoji = false
+			}
+		}
+	default:
+		pc.violation(path+".type", fmt.Sprintf("unknown text type %q", fixed.Type), false)
+	}
+	fixed.Text = pc.length(path+".text", fixed.Text, limit)
+	return &fixed
+}
+
+// length checks a text length in characters, truncating it in fix mode.
+func (pc *payloadChecker) length(path, s string, limit int) string {
+	n := utf8.RuneCountInString(s)
+	if n <= limit {
+		return s
+	}
+	pc.violation(path, fmt.Sprintf("%d characters exceed the limit of %d", n, limit), pc.fix)
+	if !pc.fix {
+		return s
+	}
+	return truncateRunes(s, limit)
+}
+
+// url checks an optional URL.
+func (pc *payloadChecker) url(path, raw string) {
+	if raw == "" {
+		return
+	}
+	if err := checkURL(raw); err != nil {
+		pc.violation(path, err.Error(), false)
+	}
+}
+
+// checkURL reports whether raw is an absolute HTTP(S) URL of at most maxURLLength.
+func checkURL(raw string) error {
+	if len(raw) > maxURLLength {
+		return fmt.Errorf("URL of %d characters exceeds the limit of %d", len(raw), maxURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", raw)
+	}
+	return nil
+}
+
+// truncateRunes shortens s to at most limit characters, ending with an ellipsis.
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
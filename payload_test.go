@@ -0,0 +1,224 @@
+// Package main provides tests for payload validation.
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/relicta-tech/plugin-slack/slacktest"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestPreparePayload tests limit checks, repairs and error paths.
+func TestPreparePayload(t *testing.T) {
+	long := func(n int) string { return strings.Repeat("é", n) }
+	fields := func(n int) []*TextObject {
+		out := make([]*TextObject, n)
+		for i := range out {
+			out[i] = mrkdwnText("field")
+		}
+		return out
+	}
+	blocks := func(n int) []any {
+		out := make([]any, n)
+		for i := range out {
+			out[i] = Block{Type: "divider"}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		msg     SlackMessage
+		fix     string // expected repair, empty if none
+		problem string // expected error in strict mode
+		check   func(t *testing.T, msg SlackMessage)
+	}{
+		{
+			name: "field text",
+			msg: SlackMessage{Text: "t", Blocks: []any{
+				Block{Type: "header", Text: plainText("h")},
+				Block{Type: "divider"},
+				Block{Type: "section", Text: mrkdwnText("s")},
+				Block{Type: "section", Fields: []*TextObject{mrkdwnText("a"), mrkdwnText("b"), mrkdwnText(long(2412))}},
+			}},
+			fix:     "blocks[3].fields[2].text: 2412 characters exceed the limit of 2000",
+			problem: "blocks[3].fields[2].text: 2412 characters exceed the limit of 2000",
+			check: func(t *testing.T, msg SlackMessage) {
+				got := msg.Blocks[3].(Block).Fields[2].Text
+				if utf8.RuneCountInString(got) != maxFieldText || !strings.HasSuffix(got, "…") {
+					t.Errorf("expected truncation to %d characters, got %d", maxFieldText, utf8.RuneCountInString(got))
+				}
+			},
+		},
+		{
+			name:    "header length and type",
+			msg:     SlackMessage{Text: "t", Blocks: []any{Block{Type: "header", Text: mrkdwnText(long(151))}}},
+			fix:     "blocks[0].text.type: must be plain_text",
+			problem: "blocks[0].text.type: must be plain_text",
+			check: func(t *testing.T, msg SlackMessage) {
+				header := msg.Blocks[0].(Block).Text
+				if header.Type != "plain_text" || utf8.RuneCountInString(header.Text) != maxHeaderText {
+					t.Errorf("unexpected header %+v", header)
+				}
+			},
+		},
+		{
+			name:    "block count",
+			msg:     SlackMessage{Text: "t", Attachments: []Attachment{{Blocks: blocks(60)}}},
+			fix:     "attachments[0].blocks: 60 blocks exceed the limit of 50",
+			problem: "attachments[0].blocks: 60 blocks exceed the limit of 50",
+			check: func(t *testing.T, msg SlackMessage) {
+				if len(msg.Attachments[0].Blocks) != maxBlocks {
+					t.Errorf("expected %d blocks, got %d", maxBlocks, len(msg.Attachments[0].Blocks))
+				}
+			},
+		},
+		{
+			name:    "field count",
+			msg:     SlackMessage{Text: "t", Blocks: []any{Block{Type: "section", Fields: fields(12)}}},
+			fix:     "blocks[0].fields: 12 fields exceed the limit of 10",
+			problem: "blocks[0].fields: 12 fields exceed the limit of 10",
+		},
+		{
+			name:    "fallback text",
+			msg:     SlackMessage{Blocks: []any{Block{Type: "header", Text: plainText("Release 1.2.0")}, Block{Type: "divider"}}},
+			fix:     "text: fallback text is required with blocks",
+			problem: "text: fallback text is required with blocks",
+			check: func(t *testing.T, msg SlackMessage) {
+				if msg.Text != "Release 1.2.0" {
+					t.Errorf("expected the header as fallback text, got %q", msg.Text)
+				}
+			},
+		},
+		{
+			name:    "emoji on mrkdwn",
+			msg:     SlackMessage{Text: "t", Blocks: []any{Block{Type: "section", Text: &TextObject{Type: "mrkdwn", Text: "x", Emoji: true}}}},
+			fix:     "blocks[0].text.emoji: emoji is only allowed on plain_text",
+			problem: "blocks[0].text.emoji: emoji is only allowed on plain_text",
+		},
+		{
+			name: "action elements",
+			msg: SlackMessage{Text: "t", Blocks: []any{Block{Type: "actions", Elements: []any{
+				ButtonElement{Type: "button", Text: mrkdwnText("Open"), ActionID: "open", URL: "https://example.com/release"},
+			}}}},
+			fix:     "blocks[0].elements[0].text.type: must be plain_text",
+			problem: "blocks[0].elements[0].text.type: must be plain_text",
+		},
+		{
+			name: "button URL",
+			msg: SlackMessage{Text: "t", Blocks: []any{Block{Type: "actions", Elements: []any{
+				ButtonElement{Type: "button", Text: plainText("Open"), ActionID: "open", URL: "ftp://example.com/release"},
+			}}}},
+			problem: `blocks[0].elements[0].url: "ftp://example.com/release" is not an absolute http(s) URL`,
+		},
+		{
+			name:    "attachment link",
+			msg:     SlackMessage{Attachments: []Attachment{{Title: "t", TitleLink: "/releases/1.2.0"}}},
+			problem: `attachments[0].title_link: "/releases/1.2.0" is not an absolute http(s) URL`,
+		},
+		{
+			name:    "empty section",
+			msg:     SlackMessage{Text: "t", Blocks: []any{Block{Type: "section"}}},
+			problem: "blocks[0]: section needs text or fields",
+		},
+		{
+			name: "button style and action ID",
+			msg: SlackMessage{Text: "t", Blocks: []any{Block{Type: "actions", Elements: []any{
+				ButtonElement{Type: "button", Text: plainText("Go"), Style: "green"},
+			}}}},
+			problem: "blocks[0].elements[0].action_id: action_id must have 1 to 255 characters; blocks[0].elements[0].style: style \"green\" must be primary or danger",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := plainTextRendering(tt.msg)
+
+			msg, fixes, err := (&Config{PayloadValidation: payloadFix}).preparePayload(tt.msg)
+			switch {
+			case tt.fix == "":
+				if err == nil || !strings.Contains(err.Error(), tt.problem) {
+					t.Errorf("expected %q to fail in fix mode, got %v", tt.problem, err)
+				}
+			case err != nil:
+				t.Errorf("expected a repair, got %v", err)
+			case !strings.Contains(strings.Join(fixes, "\n"), tt.fix):
+				t.Errorf("expected repair %q, got %v", tt.fix, fixes)
+			case tt.check != nil:
+				tt.check(t, msg)
+			}
+			if plainTextRendering(tt.msg) != original {
+				t.Error("expected the original message to be left unchanged")
+			}
+
+			_, _, err = (&Config{PayloadValidation: payloadStrict}).preparePayload(tt.msg)
+			if err == nil || !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("expected %q in strict mode, got %v", tt.problem, err)
+			}
+		})
+	}
+
+	// Messages the plugin builds are within the limits.
+	cfg := &Config{Format: formatBlocks}
+	for _, msg := range []SlackMessage{
+		buildApprovalMessage(cfg, "r1", plugin.ReleaseContext{Version: "1.2.0", Branch: "main"}),
+		buildErrorMessage(cfg, plugin.ReleaseContext{Version: "1.2.0"}, deliveryPlan{}),
+	} {
+		if _, fixes, err := cfg.preparePayload(msg); err != nil || len(fixes) > 0 {
+			t.Errorf("expected a valid payload, got %v / %v", fixes, err)
+		}
+	}
+}
+
+// TestPayloadValidationOnSend tests that payloads are checked before every
+// send and in dry runs.
+func TestPayloadValidationOnSend(t *testing.T) {
+	p, server := newFakeSlack(t)
+	config := map[string]any{
+		"webhook":         testWebhookURL,
+		"notify_on_start": true,
+		"start_template":  strings.Repeat("a", maxMessageText+10),
+	}
+	execute := func(dryRun bool) *plugin.ExecuteResponse {
+		t.Helper()
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPreInit, Config: config, Context: plugin.ReleaseContext{Version: "1.2.0"}, DryRun: dryRun,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := execute(false); !resp.Success {
+		t.Fatalf("expected the announcement to be repaired and sent, got %+v", resp)
+	}
+	calls := server.Calls(slacktest.Webhook)
+	if len(calls) != 1 || calls[0].Body["text"] != strings.Repeat("a", maxMessageText-1)+"…" {
+		t.Errorf("expected the text to be truncated, got %d requests", len(calls))
+	}
+
+	resp := execute(true)
+	previews, _ := resp.Outputs["previews"].([]any)
+	if len(previews) != 1 {
+		t.Fatalf("unexpected previews %v", resp.Outputs)
+	}
+	fixes, _ := previews[0].(map[string]any)["fixes"].([]any)
+	if len(fixes) != 1 || !strings.HasPrefix(fixes[0].(string), "text: 40010 characters exceed the limit of 40000") {
+		t.Errorf("expected the repair in the preview, got %v", fixes)
+	}
+
+	config["payload_validation"] = payloadStrict
+	for _, dryRun := range []bool{true, false} {
+		resp := execute(dryRun)
+		if resp.Success || !strings.Contains(resp.Error, "invalid Slack payload: text: 40010 characters") {
+			t.Errorf("dry run %v: expected a strict failure, got %q", dryRun, resp.Error)
+		}
+	}
+	if len(server.Calls(slacktest.Webhook)) != 1 {
+		t.Error("expected nothing to be sent in strict mode")
+	}
+}
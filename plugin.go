@@ -8,14 +8,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-hclog"
 	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 
 	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
 	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
@@ -34,9 +35,9 @@ var defaultHTTPClient = &http.Client{
 		if req.URL.Scheme != "https" {
 			return fmt.Errorf("redirect to non-HTTPS URL not allowed")
 		}
-		// Prevent redirect away from hooks.slack.com (SSRF protection)
-		if req.URL.Host != "hooks.slack.com" {
-			return fmt.Errorf("redirect away from hooks.slack.com not allowed")
+		// Prevent redirect away from Slack hosts (SSRF protection)
+		if !allowedSlackHosts[req.URL.Host] {
+			return fmt.Errorf("redirect away from %s not allowed", strings.Join(slackHostNames(), ", "))
 		}
 		return nil
 	},
@@ -50,13 +51,46 @@ var defaultHTTPClient = &http.Client{
 	},
 }
 
+// allowedSlackHosts lists the hosts the plugin may be redirected to.
+var allowedSlackHosts = map[string]bool{
+	"files.slack.com": true,
+	"hooks.slack.com": true,
+	"slack.com":       true,
+}
+
+// slackHostNames returns the allowed Slack hosts in a stable order.
+func slackHostNames() []string {
+	names := make([]string, 0, len(allowedSlackHosts))
+	for h := range allowedSlackHosts {
+		names = append(names, h)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // SlackPlugin implements the Slack notification plugin.
-type SlackPlugin struct{}
+type SlackPlugin struct {
+	// client sends requests to Slack; nil uses defaultHTTPClient.
+	client *http.Client
+	// baseURL, when set, replaces the origin of the Web API and of Slack
+	// webhook URLs, e.g. to point the plugin at a slacktest server.
+	baseURL string
+	// clock returns the current time; nil uses time.Now.
+	clock func() time.Time
+	// stateDir is the default state directory; empty uses .relicta/slack.
+	stateDir string
+	// logOutput receives the log entries; nil uses stderr.
+	logOutput io.Writer
+	// limiter paces requests to Slack; nil uses defaultRateLimiter.
+	limiter *rateLimiter
+}
 
 // Config represents the Slack plugin configuration.
 type Config struct {
 	// WebhookURL is the Slack webhook URL.
 	WebhookURL string `json:"webhook,omitempty"`
+	// BotToken is a Slack bot token; when set, messages are sent with chat.postMessage.
+	BotToken string `json:"bot_token,omitempty"`
 	// Channel is the channel to post to (overrides webhook default).
 	Channel string `json:"channel,omitempty"`
 	// Username is the bot username.
@@ -69,10 +103,112 @@ type Config struct {
 	NotifyOnSuccess bool `json:"notify_on_success"`
 	// NotifyOnError sends notification on failed release.
 	NotifyOnError bool `json:"notify_on_error"`
+	// NotifyOnStart announces that a release is starting (pre-init).
+	NotifyOnStart bool `json:"notify_on_start"`
+	// NotifyOnPlan announces the planned version and changes (post-plan).
+	NotifyOnPlan bool `json:"notify_on_plan"`
+	// NotifyOnPublishing announces that publishing is about to begin (pre-publish).
+	NotifyOnPublishing bool `json:"notify_on_publishing"`
+	// StartTemplate is the template for the release starting announcement.
+	StartTemplate string `json:"start_template,omitempty"`
+	// PlanTemplate is the template for the release planned announcement.
+	PlanTemplate string `json:"plan_template,omitempty"`
+	// PublishingTemplate is the template for the publishing now announcement.
+	PublishingTemplate string `json:"publishing_template,omitempty"`
 	// IncludeChangelog includes changelog in the notification.
 	IncludeChangelog bool `json:"include_changelog"`
 	// Mentions is a list of users/groups to mention.
 	Mentions []string `json:"mentions,omitempty"`
+	// Preflight enables live connectivity checks during validation.
+	Preflight bool `json:"preflight"`
+	// PreflightChannel is a sandbox channel that receives a test post during webhook preflight.
+	PreflightChannel string `json:"preflight_channel,omitempty"`
+	// ApprovalRequired blocks pre-publish until the release is approved in Slack.
+	ApprovalRequired bool `json:"approval_required"`
+	// ApprovalSigningSecret verifies Slack interactivity requests.
+	ApprovalSigningSecret string `json:"approval_signing_secret,omitempty"`
+	// ApprovalListenAddr is the address of the interactivity callback listener.
+	ApprovalListenAddr string `json:"approval_listen_addr,omitempty"`
+	// ApprovalTimeout is how long to wait for a decision.
+	ApprovalTimeout time.Duration `json:"approval_timeout,omitempty"`
+	// Approvers restricts who may approve or reject (user IDs or usernames).
+	Approvers []string `json:"approvers,omitempty"`
+	// StateDir is where message identifiers are kept between hooks.
+	StateDir string `json:"state_dir,omitempty"`
+	// Reactions marks the release announcement with a reaction per release state.
+	Reactions bool `json:"reactions"`
+	// ReactionPending is the emoji added when the release is published.
+	ReactionPending string `json:"reaction_pending,omitempty"`
+	// ReactionSuccess is the emoji that replaces the pending one on success.
+	ReactionSuccess string `json:"reaction_success,omitempty"`
+	// ReactionFailure is the emoji that replaces the pending one on failure.
+	ReactionFailure string `json:"reaction_failure,omitempty"`
+	// UploadReleaseNotes shares the full release notes as a snippet in the announcement thread.
+	UploadReleaseNotes bool `json:"upload_release_notes"`
+	// Artifacts are glob patterns of released files listed, with checksums, in a JSON manifest.
+	Artifacts []string `json:"artifacts,omitempty"`
+	// ChannelTopicTemplate sets the marked part of the channel topic after a release.
+	ChannelTopicTemplate string `json:"channel_topic_template,omitempty"`
+	// ChannelBookmarkTemplate is the title of a channel bookmark kept pointing at the latest release.
+	ChannelBookmarkTemplate string `json:"channel_bookmark_template,omitempty"`
+	// ChannelBookmarkLink is the template for the bookmark link.
+	ChannelBookmarkLink string `json:"channel_bookmark_link,omitempty"`
+	// ChannelTopicMarker identifies the plugin's topic segment and bookmark.
+	ChannelTopicMarker string `json:"channel_topic_marker,omitempty"`
+	// QuietHours is a daily window in which messages do not ping anyone.
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	// ScheduleAt is a template rendering the time messages are delivered at.
+	ScheduleAt string `json:"schedule_at,omitempty"`
+	// Locale is the language of notifications, as a BCP 47 tag such as "de".
+	Locale string `json:"locale,omitempty"`
+	// Destinations are additional places notifications are sent to.
+	Destinations []Destination `json:"destinations,omitempty"`
+	// Format is the layout of notifications: attachments or blocks.
+	Format string `json:"format,omitempty"`
+	// Themes override the built-in success themes, keyed by theme name.
+	Themes map[string]Theme `json:"themes,omitempty"`
+	// NotifyOnPrerelease sends notifications for prereleases and builds.
+	NotifyOnPrerelease bool `json:"notify_on_prerelease"`
+	// PrereleaseChannel is the channel for prerelease and build notifications.
+	PrereleaseChannel string `json:"prerelease_channel,omitempty"`
+	// PrereleaseTemplate replaces the success notification of prereleases and builds.
+	PrereleaseTemplate string `json:"prerelease_template,omitempty"`
+	// History records each finished release in the state directory.
+	History bool `json:"history"`
+	// Mode is "notify" for release notifications or "digest" for a release digest.
+	Mode string `json:"mode,omitempty"`
+	// DigestDays is the number of days a digest covers.
+	DigestDays int `json:"digest_days,omitempty"`
+	// Metrics adds cadence, change volume and delivery metrics from the
+	// release history to success notifications.
+	Metrics bool `json:"metrics"`
+	// PreviewFile is where dry runs write the rendered messages.
+	PreviewFile string `json:"preview_file,omitempty"`
+	// PayloadValidation is "fix" to repair payloads exceeding Slack's limits,
+	// or "strict" to reject them.
+	PayloadValidation string `json:"payload_validation,omitempty"`
+	// LogLevel is the minimum level logged: trace, debug, info, warn, error or off.
+	LogLevel string `json:"log_level,omitempty"`
+	// Audit records every delivery in an append-only JSONL audit log.
+	Audit bool `json:"audit"`
+	// AuditFile is the audit log; empty uses state_dir/audit.jsonl.
+	AuditFile string `json:"audit_file,omitempty"`
+	// AuditMaxSizeMB is the size in MiB at which the audit log is rotated.
+	AuditMaxSizeMB int `json:"audit_max_size_mb,omitempty"`
+	// AuditMaxFiles is the number of rotated audit logs kept.
+	AuditMaxFiles int `json:"audit_max_files,omitempty"`
+	// AuditHMACKey, when set, chains the audit entries with HMAC-SHA256.
+	AuditHMACKey string `json:"audit_hmac_key,omitempty"`
+	// Vars are values, such as other plugins' outputs, available to templates as .Vars.
+	Vars map[string]string `json:"vars,omitempty"`
+	// ReleaseURL is a template for the release page; empty derives it from the repository.
+	ReleaseURL string `json:"release_url,omitempty"`
+	// DownloadsURL is a template for the downloads page.
+	DownloadsURL string `json:"downloads_url,omitempty"`
+	// Assets are release downloads available to templates as .Assets.
+	Assets []Asset `json:"assets,omitempty"`
+	// ReleaseButtons adds "Release page" and "Downloads" buttons to success notifications.
+	ReleaseButtons bool `json:"release_buttons"`
 }
 
 // SlackMessage represents a Slack message payload.
@@ -96,6 +232,9 @@ type Attachment struct {
 	Footer     string  `json:"footer,omitempty"`
 	FooterIcon string  `json:"footer_icon,omitempty"`
 	Ts         int64   `json:"ts,omitempty"`
+	Blocks     []any   `json:"blocks,omitempty"`
+	// Actions are link buttons; the blocks format lays them out as an actions block.
+	Actions []AttachmentAction `json:"actions,omitempty"`
 }
 
 // Field represents a field in a Slack attachment.
@@ -113,6 +252,9 @@ func (p *SlackPlugin) GetInfo() plugin.Info {
 		Description: "Send Slack notifications for releases",
 		Author:      "Relicta Team",
 		Hooks: []plugin.Hook{
+			plugin.HookPreInit,
+			plugin.HookPostPlan,
+			plugin.HookPrePublish,
 			plugin.HookPostPublish,
 			plugin.HookOnSuccess,
 			plugin.HookOnError,
@@ -121,25 +263,212 @@ func (p *SlackPlugin) GetInfo() plugin.Info {
 			"type": "object",
 			"properties": {
 				"webhook": {"type": "string", "description": "Slack webhook URL (or use SLACK_WEBHOOK_URL env)"},
+				"bot_token": {"type": "string", "description": "Slack bot token for Web API mode (or use SLACK_BOT_TOKEN env)"},
 				"channel": {"type": "string", "description": "Channel to post to"},
 				"username": {"type": "string", "description": "Bot username", "default": "Relicta"},
 				"icon_emoji": {"type": "string", "description": "Bot icon emoji", "default": ":rocket:"},
 				"icon_url": {"type": "string", "description": "Bot icon URL"},
 				"notify_on_success": {"type": "boolean", "description": "Notify on success", "default": true},
 				"notify_on_error": {"type": "boolean", "description": "Notify on error", "default": true},
+				"notify_on_start": {"type": "boolean", "description": "Announce that a release is starting (pre-init)", "default": false},
+				"notify_on_plan": {"type": "boolean", "description": "Announce the planned release (post-plan)", "default": false},
+				"notify_on_publishing": {"type": "boolean", "description": "Announce that publishing is starting (pre-publish)", "default": false},
+				"start_template": {"type": "string", "description": "Go template for the release starting announcement"},
+				"plan_template": {"type": "string", "description": "Go template for the release planned announcement"},
+				"publishing_template": {"type": "string", "description": "Go template for the publishing announcement"},
 				"include_changelog": {"type": "boolean", "description": "Include changelog", "default": false},
-				"mentions": {"type": "array", "items": {"type": "string"}, "description": "Users/groups to mention"}
+				"mentions": {"type": "array", "items": {"type": "string"}, "description": "Users/groups to mention"},
+				"preflight": {"type": "boolean", "description": "Check connectivity and permissions during validation", "default": false},
+				"preflight_channel": {"type": "string", "description": "Sandbox channel for a webhook preflight test message"},
+				"approval_required": {"type": "boolean", "description": "Require approval in Slack before publishing", "default": false},
+				"approval_signing_secret": {"type": "string", "description": "Slack app signing secret (or use SLACK_SIGNING_SECRET env)"},
+				"approval_listen_addr": {"type": "string", "description": "Listen address for Slack interactivity callbacks", "default": ":3000"},
+				"approval_timeout": {"type": "integer", "description": "Seconds to wait for an approval decision", "default": 3600, "minimum": 1},
+				"approvers": {"type": "array", "items": {"type": "string"}, "description": "User IDs or usernames allowed to approve"},
+				"state_dir": {"type": "string", "description": "Directory for state kept between hooks", "default": ".relicta/slack"},
+				"reactions": {"type": "boolean", "description": "Show release state as reactions on the announcement (bot token only)", "default": false},
+				"reaction_pending": {"type": "string", "description": "Reaction while the release is in progress", "default": "hourglass"},
+				"reaction_success": {"type": "string", "description": "Reaction when the release succeeds", "default": "white_check_mark"},
+				"reaction_failure": {"type": "string", "description": "Reaction when the release fails", "default": "x"},
+				"upload_release_notes": {"type": "boolean", "description": "Share the full release notes as a file in the announcement thread (bot token only)", "default": false},
+				"artifacts": {"type": "array", "items": {"type": "string"}, "description": "Glob patterns of artifacts to list with checksums in a manifest file (bot token only)"},
+				"channel_topic_template": {"type": "string", "description": "Go template for the channel topic segment set after a release (bot token only)"},
+				"channel_bookmark_template": {"type": "string", "description": "Go template for the title of the latest release bookmark (bot token only)"},
+				"channel_bookmark_link": {"type": "string", "description": "Go template for the bookmark link", "default": "{{.ReleaseURL}}"},
+				"channel_topic_marker": {"type": "string", "description": "Marks the topic segment and bookmark managed by the plugin", "default": "📦"},
+				"quiet_hours": {
+					"type": "object",
+					"description": "Daily window in which messages do not ping anyone",
+					"properties": {
+						"timezone": {"type": "string", "description": "IANA time zone of the window", "default": "UTC"},
+						"start": {"type": "string", "description": "Start of the window (HH:MM)"},
+						"end": {"type": "string", "description": "End of the window (HH:MM)"},
+						"mode": {"type": "string", "enum": ["silent", "schedule"], "description": "Send without mentions, or schedule for the end of the window (bot token only)", "default": "silent"}
+					},
+					"additionalProperties": false
+				},
+				"schedule_at": {"type": "string", "description": "Go template rendering the delivery time as RFC 3339 or Unix seconds (bot token only)"},
+				"locale": {"type": "string", "description": "Notification language (en, de, fr, es, ja, pt)", "default": "en"},
+				"destinations": {
+					"type": "array",
+					"description": "Additional destinations, each overriding webhook, channel or locale",
+					"items": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string", "description": "Key of this destination in the destinations output (default: its channel)"},
+							"webhook": {"type": "string", "description": "Webhook URL for this destination"},
+							"channel": {"type": "string", "description": "Channel to post to"},
+							"locale": {"type": "string", "description": "Notification language"}
+						},
+						"additionalProperties": false
+					}
+				},
+				"notify_on_prerelease": {"type": "boolean", "description": "Send notifications for prereleases and builds (errors are always sent)", "default": true},
+				"prerelease_channel": {"type": "string", "description": "Channel for prerelease and build notifications"},
+				"prerelease_template": {"type": "string", "description": "Go template replacing the success notification of prereleases and builds"},
+				"history": {"type": "boolean", "description": "Record each finished release in state_dir/history.jsonl", "default": false},
+				"mode": {"type": "string", "enum": ["notify", "digest"], "description": "Send release notifications, or a digest of recorded releases", "default": "notify"},
+				"digest_days": {"type": "integer", "description": "Number of days covered by a digest", "default": 7, "minimum": 1},
+				"metrics": {"type": "boolean", "description": "Add release cadence, change volume and DORA metrics from the release history to success notifications", "default": false},
+				"preview_file": {"type": "string", "description": "JSON file that dry runs write the rendered messages to"},
+				"payload_validation": {"type": "string", "enum": ["fix", "strict"], "description": "Repair (fix) or reject (strict) messages exceeding Slack's Block Kit limits", "default": "fix"},
+				"audit": {"type": "boolean", "description": "Record every delivery in an append-only JSONL audit log", "default": false},
+				"audit_file": {"type": "string", "description": "Audit log file (default: state_dir/audit.jsonl)"},
+				"audit_max_size_mb": {"type": "integer", "description": "Size in MiB at which the audit log is rotated", "default": 10, "minimum": 1},
+				"audit_max_files": {"type": "integer", "description": "Number of rotated audit logs kept", "default": 5, "minimum": 1},
+				"audit_hmac_key": {"type": "string", "description": "Key chaining audit entries with HMAC-SHA256 to detect tampering (or use SLACK_AUDIT_HMAC_KEY env)"},
+				"vars": {"type": "object", "additionalProperties": {"type": "string"}, "description": "Values available to templates as .Vars, such as other plugins' outputs"},
+				"release_url": {"type": "string", "description": "Go template for the release page, such as the GitHub release created by another plugin (default: derived from the repository URL and tag)"},
+				"downloads_url": {"type": "string", "description": "Go template for the downloads page"},
+				"assets": {
+					"type": "array",
+					"description": "Release downloads available to templates as .Assets",
+					"items": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string", "description": "Link text"},
+							"url": {"type": "string", "description": "Go template for the download link"}
+						},
+						"required": ["url"],
+						"additionalProperties": false
+					}
+				},
+				"release_buttons": {"type": "boolean", "description": "Add Release page and Downloads buttons to success notifications", "default": false},
+				"log_level": {"type": "string", "enum": ["trace", "debug", "info", "warn", "error", "off"], "description": "Minimum level of the plugin's log entries (or use SLACK_LOG_LEVEL env)", "default": "info"},
+				"format": {"type": "string", "enum": ["attachments", "blocks"], "description": "Notification layout: legacy attachments or Block Kit", "default": "attachments"},
+				"themes": {
+					"type": "object",
+					"description": "Overrides for the success themes (color, emoji, title template, mentions)",
+					"properties": {
+						"default": {"type": "object", "properties": {"color": {"type": "string"}, "emoji": {"type": "string"}, "title": {"type": "string"}, "mentions": {"type": "array", "items": {"type": "string"}}}, "additionalProperties": false},
+						"major": {"type": "object", "properties": {"color": {"type": "string"}, "emoji": {"type": "string"}, "title": {"type": "string"}, "mentions": {"type": "array", "items": {"type": "string"}}}, "additionalProperties": false},
+						"minor": {"type": "object", "properties": {"color": {"type": "string"}, "emoji": {"type": "string"}, "title": {"type": "string"}, "mentions": {"type": "array", "items": {"type": "string"}}}, "additionalProperties": false},
+						"patch": {"type": "object", "properties": {"color": {"type": "string"}, "emoji": {"type": "string"}, "title": {"type": "string"}, "mentions": {"type": "array", "items": {"type": "string"}}}, "additionalProperties": false},
+						"prerelease": {"type": "object", "properties": {"color": {"type": "string"}, "emoji": {"type": "string"}, "title": {"type": "string"}, "mentions": {"type": "array", "items": {"type": "string"}}}, "additionalProperties": false},
+						"breaking": {"type": "object", "properties": {"color": {"type": "string"}, "emoji": {"type": "string"}, "title": {"type": "string"}, "mentions": {"type": "array", "items": {"type": "string"}}}, "additionalProperties": false}
+					},
+					"additionalProperties": false
+				}
 			},
-			"required": ["webhook"]
+			"additionalProperties": false
 		}`,
 	}
 }
 
 // Execute runs the plugin for a given hook.
+// Secrets are redacted from the response before it is returned to the host.
+// Every execution logs under a correlation ID, which is returned in the
+// correlation_id output.
 func (p *SlackPlugin) Execute(ctx context.Context, req plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
 	cfg := p.parseConfig(req.Config)
+	r := newRedactor(cfg.secrets()...)
+	correlationID := newCorrelationID()
+	log := p.requestLogger(cfg, r, correlationID).With("hook", string(req.Hook))
+	ctx = hclog.WithContext(ctx, log)
+	ctx = withAuditScope(ctx, auditScope{hook: req.Hook, release: req.Context, correlationID: correlationID})
+
+	start := time.Now()
+	log.Debug("executing hook", "dry_run", req.DryRun)
+	resp, err := p.execute(ctx, cfg, req)
+	switch {
+	case err != nil:
+		log.Error("hook failed", "error", err)
+	case resp != nil && !resp.Success:
+		log.Error("hook failed", "error", resp.Error)
+	case resp != nil:
+		log.Info(resp.Message, "duration_ms", time.Since(start).Milliseconds())
+	}
+
+	if resp != nil {
+		if resp.Outputs == nil {
+			resp.Outputs = map[string]any{}
+		}
+		resp.Outputs[correlationIDOutput] = correlationID
+	}
+	return r.Response(resp), err
+}
+
+// execute dispatches a hook to the matching notification, recording
+// finished releases in the history. In digest mode it posts the digest on
+// digestHook instead, and ignores the other hooks.
+func (p *SlackPlugin) execute(ctx context.Context, cfg *Config, req plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
+	if cfg.Mode == modeDigest {
+		if req.Hook != digestHook {
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: fmt.Sprintf("Hook %s not handled in digest mode", req.Hook),
+			}, nil
+		}
+		return p.sendDigest(ctx, cfg, p.now(), req.DryRun)
+	}
+	if !req.DryRun {
+		recordRelease(ctx, cfg, req.Hook, req.Context, p.now())
+	}
+
+	if cfg.PrereleaseChannel != "" && releaseStability(req.Context) != stabilityStable {
+		cfg.Channel = cfg.PrereleaseChannel
+	}
 
 	switch req.Hook {
+	case plugin.HookPreInit:
+		if !cfg.NotifyOnStart {
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: "Start notification disabled",
+			}, nil
+		}
+		return p.sendAnnouncement(ctx, cfg, "start", cfg.StartTemplate, req.Context, req.DryRun)
+
+	case plugin.HookPostPlan:
+		if !cfg.NotifyOnPlan {
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: "Plan notification disabled",
+			}, nil
+		}
+		return p.sendAnnouncement(ctx, cfg, "plan", cfg.PlanTemplate, req.Context, req.DryRun)
+
+	case plugin.HookPrePublish:
+		var approval *plugin.ExecuteResponse
+		if cfg.ApprovalRequired {
+			resp, err := p.requestApproval(ctx, cfg, req.Context, req.DryRun)
+			if err != nil || !resp.Success || !cfg.NotifyOnPublishing {
+				return resp, err
+			}
+			approval = resp
+		}
+		if !cfg.NotifyOnPublishing {
+			return &plugin.ExecuteResponse{
+				Success: true,
+				Message: "Publishing notification disabled",
+			}, nil
+		}
+		resp, err := p.sendAnnouncement(ctx, cfg, "publishing", cfg.PublishingTemplate, req.Context, req.DryRun)
+		if err != nil || approval == nil {
+			return resp, err
+		}
+		return withApproval(approval, resp), nil
+
 	case plugin.HookPostPublish, plugin.HookOnSuccess:
 		if !cfg.NotifyOnSuccess {
 			return &plugin.ExecuteResponse{
@@ -147,7 +476,7 @@ func (p *SlackPlugin) Execute(ctx context.Context, req plugin.ExecuteRequest) (*
 				Message: "Success notification disabled",
 			}, nil
 		}
-		return p.sendSuccessNotification(ctx, cfg, req.Context, req.DryRun)
+		return p.sendSuccessNotification(ctx, cfg, req.Hook, req.Context, req.DryRun)
 
 	case plugin.HookOnError:
 		if !cfg.NotifyOnError {
@@ -156,7 +485,7 @@ func (p *SlackPlugin) Execute(ctx context.Context, req plugin.ExecuteRequest) (*
 				Message: "Error notification disabled",
 			}, nil
 		}
-		return p.sendErrorNotification(ctx, cfg, req.Context, req.DryRun)
+		return p.sendErrorNotification(ctx, cfg, req.Hook, req.Context, req.DryRun)
 
 	default:
 		return &plugin.ExecuteResponse{
@@ -174,7 +503,9 @@ func buildSlackMentions(mentions []string) string {
 	// Slack mentions are like <@U123456> for users or <!subteam^S123456> for groups
 	var formatted []string
 	for _, m := range mentions {
-		if strings.HasPrefix(m, "<@") || strings.HasPrefix(m, "<!") {
+		if special, ok := specialMentions[m]; ok {
+			formatted = append(formatted, special)
+		} else if strings.HasPrefix(m, "<@") || strings.HasPrefix(m, "<!") {
 			formatted = append(formatted, m)
 		} else if strings.HasPrefix(m, "@") {
 			formatted = append(formatted, fmt.Sprintf("<@%s>", strings.TrimPrefix(m, "@")))
@@ -185,28 +516,45 @@ func buildSlackMentions(mentions []string) string {
 	return strings.Join(formatted, " ")
 }
 
-// sendSuccessNotification sends a success notification.
-func (p *SlackPlugin) sendSuccessNotification(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+// buildSuccessMessage builds the success notification in the target's
+// language, styled by the release's theme. metrics may be nil.
+func buildSuccessMessage(cfg *Config, releaseCtx plugin.ReleaseContext, plan deliveryPlan, metrics *releaseMetrics) (SlackMessage, error) {
+	pr := cfg.printer()
+	lang := resolveLocale(cfg.Locale)
+	theme := cfg.theme(releaseCtx)
+
 	// Build message
-	title := fmt.Sprintf(":rocket: Release %s Published!", releaseCtx.Version)
+	title, err := theme.title(cfg, releaseCtx)
+	if err != nil {
+		return SlackMessage{}, err
+	}
 
 	fields := []Field{
-		{Title: "Version", Value: releaseCtx.Version, Short: true},
-		{Title: "Release Type", Value: cases.Title(language.English).String(releaseCtx.ReleaseType), Short: true},
-		{Title: "Branch", Value: releaseCtx.Branch, Short: true},
-		{Title: "Tag", Value: releaseCtx.TagName, Short: true},
+		{Title: translate(pr, "Version"), Value: releaseCtx.Version, Short: true},
+		{Title: translate(pr, "Release Type"), Value: cases.Title(lang).String(releaseTypeName(pr, releaseCtx.ReleaseType)), Short: true},
+		{Title: translate(pr, "Branch"), Value: releaseCtx.Branch, Short: true},
+		{Title: translate(pr, "Tag"), Value: releaseCtx.TagName, Short: true},
+	}
+
+	if prev := promotedFrom(releaseCtx); prev != "" {
+		fields = append(fields, Field{Title: translate(pr, "Promoted From"), Value: prev, Short: true})
 	}
 
 	if releaseCtx.Changes != nil {
-		features := len(releaseCtx.Changes.Features)
-		fixes := len(releaseCtx.Changes.Fixes)
-		breaking := len(releaseCtx.Changes.Breaking)
+		fields = append(fields, Field{Title: translate(pr, "Changes"), Value: changeSummary(pr, releaseCtx.Changes), Short: false})
+	}
 
-		summary := fmt.Sprintf("%d features, %d fixes", features, fixes)
-		if breaking > 0 {
-			summary += fmt.Sprintf(", %d breaking changes", breaking)
-		}
-		fields = append(fields, Field{Title: "Changes", Value: summary, Short: false})
+	if metrics != nil {
+		fields = append(fields, Field{Title: translate(pr, "Metrics"), Value: strings.Join(metrics.lines(pr), "\n"), Short: false})
+	}
+
+	links, err := cfg.linkData(releaseCtx)
+	if err != nil {
+		return SlackMessage{}, err
+	}
+	var actions []AttachmentAction
+	if cfg.ReleaseButtons {
+		actions = releaseButtons(pr, links)
 	}
 
 	text := ""
@@ -220,124 +568,320 @@ func (p *SlackPlugin) sendSuccessNotification(ctx context.Context, cfg *Config,
 		text = html.EscapeString(notes)
 	}
 
-	// Add mentions
-	mentionText := buildSlackMentions(cfg.Mentions)
+	// Add mentions, unless it is quiet hours
+	return cfg.composeMessage(plan.mentions(theme.mentions(cfg)), Attachment{
+		Color:   theme.Color,
+		Title:   title,
+		Text:    text,
+		Fields:  fields,
+		Footer:  "Relicta",
+		Ts:      plan.Now.Unix(),
+		Actions: actions,
+	}), nil
+}
 
+// composeMessage lays out a notification in the configured format. In the
+// blocks format the attachment only provides the colour bar around the blocks.
+func (c *Config) composeMessage(mentions string, att Attachment) SlackMessage {
 	msg := SlackMessage{
-		Channel:   cfg.Channel,
-		Username:  cfg.Username,
-		IconEmoji: cfg.IconEmoji,
-		IconURL:   cfg.IconURL,
-		Text:      mentionText,
-		Attachments: []Attachment{
-			{
-				Color:  "good",
-				Title:  title,
-				Text:   text,
-				Fields: fields,
-				Footer: "Relicta",
-				Ts:     time.Now().Unix(),
-			},
-		},
+		Channel:   c.Channel,
+		Username:  c.Username,
+		IconEmoji: c.IconEmoji,
+		IconURL:   c.IconURL,
+		Text:      mentions,
+	}
+	if c.Format != formatBlocks {
+		msg.Attachments = []Attachment{att}
+		return msg
 	}
 
-	if dryRun {
+	// Block Kit messages need a top-level text for notifications.
+	msg.Text = strings.TrimSpace(mentions + " " + att.Title)
+	msg.Attachments = []Attachment{{
+		Color:  att.Color,
+		Blocks: attachmentBlocks(c.printer(), att),
+	}}
+	return msg
+}
+
+// sendSuccessNotification sends a success notification.
+func (p *SlackPlugin) sendSuccessNotification(ctx context.Context, cfg *Config, hook plugin.Hook, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+	if !cfg.notifiesRelease(releaseCtx) {
 		return &plugin.ExecuteResponse{
 			Success: true,
-			Message: "Would send Slack success notification",
-			Outputs: map[string]any{
-				"channel": cfg.Channel,
-				"version": releaseCtx.Version,
-			},
+			Message: "Prerelease notification disabled",
+		}, nil
+	}
+
+	plan, err := planDelivery(cfg, releaseCtx, p.now())
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
 		}, nil
 	}
 
-	if err := p.sendMessage(ctx, cfg.WebhookURL, msg); err != nil {
+	var metrics *releaseMetrics
+	if cfg.Metrics {
+		m, err := loadMetrics(ctx, cfg, releaseCtx, p.now())
+		if err != nil {
+			logger(ctx).Warn("failed to compute release metrics", "error", err)
+		} else {
+			metrics = &m
+		}
+	}
+
+	build := func(target *Config) (SlackMessage, error) {
+		if target.PrereleaseTemplate != "" && releaseStability(releaseCtx) != stabilityStable {
+			return buildAnnouncement(target, "prerelease", target.PrereleaseTemplate, releaseCtx, plan)
+		}
+		return buildSuccessMessage(target, releaseCtx, plan, metrics)
+	}
+
+	if dryRun {
+		outputs := plan.outputs()
+		outputs["channel"] = cfg.Channel
+		outputs["version"] = releaseCtx.Version
+		if metrics != nil {
+			outputs["metrics"] = metrics.outputs()
+		}
+		if cfg.BotToken != "" {
+			files, err := releaseFiles(cfg, releaseCtx)
+			if err != nil {
+				return &plugin.ExecuteResponse{
+					Success: false,
+					Error:   fmt.Sprintf("failed to prepare release files: %v", err),
+				}, nil
+			}
+			if len(files) > 0 {
+				outputs["files"] = fileNames(files)
+			}
+		}
+		return p.previewResponse(cfg, "success", "Would send Slack success notification", outputs, build), nil
+	}
+
+	sent, err := p.fanOut(ctx, cfg, plan, build)
+	if posted := sent.primary(); posted != nil {
+		p.trackMessage(ctx, cfg, hook, releaseCtx, posted)
+
+		// The release is already out; a failed upload must not fail it.
+		if err := p.uploadReleaseFiles(ctx, cfg, releaseCtx, posted); err != nil {
+			logger(ctx).Warn("failed to upload release files", "error", err)
+		}
+		if err := p.updateChannel(ctx, cfg, releaseCtx, posted); err != nil {
+			logger(ctx).Warn("failed to update channel topic or bookmark", "error", err)
+		}
+	}
+	if err != nil {
 		return &plugin.ExecuteResponse{
 			Success: false,
 			Error:   fmt.Sprintf("failed to send Slack message: %v", err),
+			Outputs: sent.outputs(),
 		}, nil
 	}
 
-	return &plugin.ExecuteResponse{
+	resp := &plugin.ExecuteResponse{
 		Success: true,
-		Message: "Sent Slack success notification",
-	}, nil
+		Message: plan.sentMessage("success"),
+		Outputs: sent.outputs(),
+	}
+	if metrics != nil {
+		resp.Outputs["metrics"] = metrics.outputs()
+	}
+	return resp, nil
 }
 
-// sendErrorNotification sends an error notification.
-func (p *SlackPlugin) sendErrorNotification(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
-	title := fmt.Sprintf(":x: Release %s Failed", releaseCtx.Version)
+// buildErrorMessage builds the error notification in the target's language.
+func buildErrorMessage(cfg *Config, releaseCtx plugin.ReleaseContext, plan deliveryPlan) SlackMessage {
+	pr := cfg.printer()
+	title := ":x: " + translate(pr, "Release %s Failed", releaseCtx.Version)
 
 	fields := []Field{
-		{Title: "Version", Value: releaseCtx.Version, Short: true},
-		{Title: "Branch", Value: releaseCtx.Branch, Short: true},
+		{Title: translate(pr, "Version"), Value: releaseCtx.Version, Short: true},
+		{Title: translate(pr, "Branch"), Value: releaseCtx.Branch, Short: true},
 	}
 
-	// Add mentions
-	mentionText := buildSlackMentions(cfg.Mentions)
+	// Add mentions, unless it is quiet hours
+	return cfg.composeMessage(plan.mentions(cfg.Mentions), Attachment{
+		Color:  "danger",
+		Title:  title,
+		Fields: fields,
+		Footer: "Relicta",
+		Ts:     plan.Now.Unix(),
+	})
+}
 
-	msg := SlackMessage{
+// sendErrorNotification sends an error notification.
+func (p *SlackPlugin) sendErrorNotification(ctx context.Context, cfg *Config, hook plugin.Hook, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+	plan, err := planDelivery(cfg, releaseCtx, p.now())
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	build := func(target *Config) (SlackMessage, error) {
+		return buildErrorMessage(target, releaseCtx, plan), nil
+	}
+
+	if dryRun {
+		return p.previewResponse(cfg, "error", "Would send Slack error notification", plan.outputs(), build), nil
+	}
+
+	sent, err := p.fanOut(ctx, cfg, plan, build)
+	if posted := sent.primary(); posted != nil {
+		p.trackMessage(ctx, cfg, hook, releaseCtx, posted)
+	}
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to send Slack message: %v", err),
+			Outputs: sent.outputs(),
+		}, nil
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: plan.sentMessage("error"),
+		Outputs: sent.outputs(),
+	}, nil
+}
+
+// buildAnnouncement renders a lifecycle announcement in the target's language.
+// Mentions follow the release's theme.
+func buildAnnouncement(cfg *Config, kind, tmpl string, releaseCtx plugin.ReleaseContext, plan deliveryPlan) (SlackMessage, error) {
+	text, err := cfg.renderTemplate(kind+"_template", tmpl, releaseCtx)
+	if err != nil {
+		return SlackMessage{}, err
+	}
+	if mentionText := plan.mentions(cfg.theme(releaseCtx).mentions(cfg)); mentionText != "" {
+		text = mentionText + " " + text
+	}
+
+	return SlackMessage{
 		Channel:   cfg.Channel,
 		Username:  cfg.Username,
 		IconEmoji: cfg.IconEmoji,
 		IconURL:   cfg.IconURL,
-		Text:      mentionText,
-		Attachments: []Attachment{
-			{
-				Color:  "danger",
-				Title:  title,
-				Fields: fields,
-				Footer: "Relicta",
-				Ts:     time.Now().Unix(),
-			},
-		},
-	}
+		Text:      text,
+	}, nil
+}
 
-	if dryRun {
+// sendAnnouncement renders and sends one of the opt-in lifecycle announcements.
+// kind names the announcement in responses ("start", "plan" or "publishing").
+func (p *SlackPlugin) sendAnnouncement(ctx context.Context, cfg *Config, kind, tmpl string, releaseCtx plugin.ReleaseContext, dryRun bool) (*plugin.ExecuteResponse, error) {
+	if !cfg.notifiesRelease(releaseCtx) {
 		return &plugin.ExecuteResponse{
 			Success: true,
-			Message: "Would send Slack error notification",
+			Message: "Prerelease notification disabled",
+		}, nil
+	}
+
+	plan, err := planDelivery(cfg, releaseCtx, p.now())
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	build := func(target *Config) (SlackMessage, error) {
+		return buildAnnouncement(target, kind, tmpl, releaseCtx, plan)
+	}
+
+	msg, err := build(cfg)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
 		}, nil
 	}
 
-	if err := p.sendMessage(ctx, cfg.WebhookURL, msg); err != nil {
+	if dryRun {
+		outputs := plan.outputs()
+		outputs["channel"] = cfg.Channel
+		outputs["text"] = msg.Text
+		return p.previewResponse(cfg, kind, fmt.Sprintf("Would send Slack %s notification", kind), outputs, build), nil
+	}
+
+	sent, err := p.fanOut(ctx, cfg, plan, build)
+	if err != nil {
 		return &plugin.ExecuteResponse{
 			Success: false,
 			Error:   fmt.Sprintf("failed to send Slack message: %v", err),
+			Outputs: sent.outputs(),
 		}, nil
 	}
 
 	return &plugin.ExecuteResponse{
 		Success: true,
-		Message: "Sent Slack error notification",
+		Message: plan.sentMessage(kind),
+		Outputs: sent.outputs(),
 	}, nil
 }
 
-// sendMessage sends a message to Slack.
+// sendMessage sends a message to Slack. Like Web API calls, it waits for
+// the webhook's rate limit and retries 429 answers with a short Retry-After.
 func (p *SlackPlugin) sendMessage(ctx context.Context, webhookURL string, msg SlackMessage) error {
 	payload, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(payload))
+	limits := webhookLimits(webhookURL)
+	for attempt := 1; ; attempt++ {
+		if err := p.rateLimiter().wait(ctx, limits); err != nil {
+			return err
+		}
+		header, status, err := p.sendWebhookMessage(ctx, webhookURL, payload, attempt)
+		d, retry := retryAfter(status, header)
+		if !retry || attempt == maxRateLimitAttempts {
+			return err
+		}
+		p.rateLimiter().pause(limits, d)
+	}
+}
+
+// sendWebhookMessage posts one message to a webhook and returns the
+// response headers and status.
+func (p *SlackPlugin) sendWebhookMessage(ctx context.Context, webhookURL string, payload []byte, attempt int) (http.Header, int, error) {
+	start := time.Now()
+	header, status, body, err := p.postWebhook(ctx, webhookURL, payload)
+	if err == nil && status != http.StatusOK {
+		if body != "" {
+			err = fmt.Errorf("slack returned status %d: %s", status, body)
+		} else {
+			err = fmt.Errorf("slack returned status %d", status)
+		}
+	}
+	code := ""
+	if status != http.StatusOK {
+		// Webhooks answer errors with the bare error code, e.g. no_text.
+		code = body
+	}
+	logRequest(ctx, webhookURL, attempt, len(payload), start, status, code, err)
+	return header, status, err
+}
+
+// postWebhook posts a raw JSON payload to a webhook and returns the
+// response headers, the status code and the (truncated) response body,
+// which carries Slack's error code.
+func (p *SlackPlugin) postWebhook(ctx context.Context, webhookURL string, payload []byte) (http.Header, int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", p.slackURL(webhookURL), bytes.NewReader(payload))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, "", fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := defaultHTTPClient.Do(req)
+	resp, err := p.httpClient().Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, 0, "", fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("slack returned status %d", resp.StatusCode)
-	}
-
-	return nil
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	return resp.Header, resp.StatusCode, strings.TrimSpace(string(body)), nil
 }
 
 // parseConfig parses the plugin configuration.
@@ -348,15 +892,80 @@ func (p *SlackPlugin) parseConfig(raw map[string]any) *Config {
 	webhook := parser.GetString("webhook", "SLACK_WEBHOOK_URL", "")
 
 	return &Config{
-		WebhookURL:       webhook,
-		Channel:          parser.GetString("channel", "", ""),
-		Username:         parser.GetString("username", "", "Relicta"),
-		IconEmoji:        parser.GetString("icon_emoji", "", ":rocket:"),
-		IconURL:          parser.GetString("icon_url", "", ""),
-		NotifyOnSuccess:  parser.GetBool("notify_on_success", true),
-		NotifyOnError:    parser.GetBool("notify_on_error", true),
-		IncludeChangelog: parser.GetBool("include_changelog", false),
-		Mentions:         parser.GetStringSlice("mentions", nil),
+		WebhookURL:         webhook,
+		BotToken:           parser.GetString("bot_token", "SLACK_BOT_TOKEN", ""),
+		Channel:            parser.GetString("channel", "", ""),
+		Username:           parser.GetString("username", "", "Relicta"),
+		IconEmoji:          parser.GetString("icon_emoji", "", ":rocket:"),
+		IconURL:            parser.GetString("icon_url", "", ""),
+		NotifyOnSuccess:    parser.GetBool("notify_on_success", true),
+		NotifyOnError:      parser.GetBool("notify_on_error", true),
+		NotifyOnStart:      parser.GetBool("notify_on_start", false),
+		NotifyOnPlan:       parser.GetBool("notify_on_plan", false),
+		NotifyOnPublishing: parser.GetBool("notify_on_publishing", false),
+		StartTemplate:      parser.GetString("start_template", "", defaultStartTemplate),
+		PlanTemplate:       parser.GetString("plan_template", "", defaultPlanTemplate),
+		PublishingTemplate: parser.GetString("publishing_template", "", defaultPublishingTemplate),
+		IncludeChangelog:   parser.GetBool("include_changelog", false),
+		Mentions:           parser.GetStringSlice("mentions", nil),
+		Preflight:          parser.GetBool("preflight", false),
+		PreflightChannel:   parser.GetString("preflight_channel", "", ""),
+
+		ApprovalRequired:      parser.GetBool("approval_required", false),
+		ApprovalSigningSecret: parser.GetString("approval_signing_secret", "SLACK_SIGNING_SECRET", ""),
+		ApprovalListenAddr:    parser.GetString("approval_listen_addr", "", ":3000"),
+		ApprovalTimeout:       time.Duration(parser.GetInt("approval_timeout", 3600)) * time.Second,
+		Approvers:             parser.GetStringSlice("approvers", nil),
+
+		StateDir:        parser.GetString("state_dir", "", p.defaultStateDir()),
+		Reactions:       parser.GetBool("reactions", false),
+		ReactionPending: parser.GetString("reaction_pending", "", "hourglass"),
+		ReactionSuccess: parser.GetString("reaction_success", "", "white_check_mark"),
+		ReactionFailure: parser.GetString("reaction_failure", "", "x"),
+
+		UploadReleaseNotes: parser.GetBool("upload_release_notes", false),
+		Artifacts:          parser.GetStringSlice("artifacts", nil),
+
+		ChannelTopicTemplate:    parser.GetString("channel_topic_template", "", ""),
+		ChannelBookmarkTemplate: parser.GetString("channel_bookmark_template", "", ""),
+		ChannelBookmarkLink:     parser.GetString("channel_bookmark_link", "", "{{.ReleaseURL}}"),
+		ChannelTopicMarker:      parser.GetString("channel_topic_marker", "", "📦"),
+
+		QuietHours: parseQuietHours(parser.GetMap("quiet_hours")),
+		ScheduleAt: parser.GetString("schedule_at", "", ""),
+
+		Locale:       parser.GetString("locale", "", "en"),
+		Destinations: parseDestinations(raw["destinations"]),
+
+		Format: parser.GetString("format", "", formatAttachments),
+		Themes: parseThemes(parser.GetMap("themes")),
+
+		NotifyOnPrerelease: parser.GetBool("notify_on_prerelease", true),
+		PrereleaseChannel:  parser.GetString("prerelease_channel", "", ""),
+		PrereleaseTemplate: parser.GetString("prerelease_template", "", ""),
+
+		History:    parser.GetBool("history", false),
+		Mode:       parser.GetString("mode", "", modeNotify),
+		DigestDays: parser.GetInt("digest_days", 7),
+		Metrics:    parser.GetBool("metrics", false),
+
+		PreviewFile: parser.GetString("preview_file", "", ""),
+
+		PayloadValidation: parser.GetString("payload_validation", "", payloadFix),
+
+		LogLevel: strings.ToLower(parser.GetString("log_level", "SLACK_LOG_LEVEL", "info")),
+
+		Audit:          parser.GetBool("audit", false),
+		AuditFile:      parser.GetString("audit_file", "", ""),
+		AuditMaxSizeMB: parser.GetInt("audit_max_size_mb", 10),
+		AuditMaxFiles:  parser.GetInt("audit_max_files", 5),
+		AuditHMACKey:   parser.GetString("audit_hmac_key", "SLACK_AUDIT_HMAC_KEY", ""),
+
+		Vars:           parseVars(parser.GetMap("vars")),
+		ReleaseURL:     parser.GetString("release_url", "", ""),
+		DownloadsURL:   parser.GetString("downloads_url", "", ""),
+		Assets:         parseAssets(raw["assets"]),
+		ReleaseButtons: parser.GetBool("release_buttons", false),
 	}
 }
 
@@ -386,28 +995,65 @@ func validateSlackWebhookURL(webhookURL string) error {
 	return nil
 }
 
-// Validate validates the plugin configuration.
-func (p *SlackPlugin) Validate(_ context.Context, config map[string]any) (*plugin.ValidateResponse, error) {
+// Validate validates the plugin configuration against the config schema
+// and Slack's naming rules. Advisory findings are returned with a "warning:"
+// code prefix and do not make the configuration invalid. When preflight is
+// enabled and the configuration is otherwise valid, the destination is
+// contacted to verify it is reachable and authorized.
+func (p *SlackPlugin) Validate(ctx context.Context, config map[string]any) (*plugin.ValidateResponse, error) {
 	vb := helpers.NewValidationBuilder()
 
-	// Get webhook URL with env fallback
-	parser := helpers.NewConfigParser(config)
-	webhook := parser.GetString("webhook", "SLACK_WEBHOOK_URL", "")
+	// Get webhook URL and bot token with env fallback
+	cfg := p.parseConfig(config)
+	webhook := cfg.WebhookURL
 
-	// Check environment fallback if not in config
-	if webhook == "" {
-		webhook = os.Getenv("SLACK_WEBHOOK_URL")
+	// Parse errors echo the URL back, and the URL is the credential.
+	redact := newRedactor(cfg.secrets()...)
+
+	if cfg.BotToken != "" {
+		if !strings.HasPrefix(cfg.BotToken, "xoxb-") && !strings.HasPrefix(cfg.BotToken, "xoxp-") {
+			vb.AddErrorWithCode("bot_token", "bot token must be a bot (xoxb-) or user (xoxp-) token", "format")
+		}
+		if cfg.Channel == "" {
+			vb.AddErrorWithCode("channel", "channel is required when using bot_token", "required")
+		}
 	}
 
 	if webhook == "" {
-		vb.AddErrorWithCode("webhook",
-			"Slack webhook URL is required (set SLACK_WEBHOOK_URL env var or configure webhook)",
-			"required")
+		if cfg.BotToken == "" {
+			vb.AddErrorWithCode("webhook",
+				"Slack webhook URL is required (set SLACK_WEBHOOK_URL env var or configure webhook), or configure bot_token",
+				"required")
+		}
 	} else {
 		if err := validateSlackWebhookURL(webhook); err != nil {
 			vb.AddErrorWithCode("webhook", err.Error(), "format")
 		}
 	}
 
-	return vb.Build(), nil
+	if cfg.ApprovalRequired && cfg.ApprovalSigningSecret == "" {
+		vb.AddErrorWithCode("approval_signing_secret",
+			"signing secret is required for approvals (set SLACK_SIGNING_SECRET env var or configure approval_signing_secret)",
+			"required")
+	}
+
+	schema, err := parseConfigSchema(p.GetInfo().ConfigSchema)
+	if err != nil {
+		return nil, err
+	}
+	validateSchema(vb, schema, "", config)
+
+	warnings := helpers.NewValidationBuilder()
+	validateSemantics(vb, warnings, config)
+	if cfg.BotToken == "" {
+		for _, key := range botTokenOnlyKeys(cfg) {
+			warnings.AddErrorWithCode(key, key+" requires bot_token and is ignored in webhook mode", warningCodePrefix+"requires_bot_token")
+		}
+	}
+
+	if cfg.Preflight && !vb.HasErrors() {
+		p.preflight(ctx, cfg, vb, warnings)
+	}
+
+	return redact.Validation(mergeWarnings(vb.Build(), warnings)), nil
 }
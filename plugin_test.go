@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/relicta-tech/plugin-slack/slacktest"
 	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
 )
 
@@ -46,6 +47,9 @@ func TestGetInfo(t *testing.T) {
 
 	t.Run("hooks", func(t *testing.T) {
 		expectedHooks := []plugin.Hook{
+			plugin.HookPreInit,
+			plugin.HookPostPlan,
+			plugin.HookPrePublish,
 			plugin.HookPostPublish,
 			plugin.HookOnSuccess,
 			plugin.HookOnError,
@@ -536,7 +540,7 @@ func TestExecute(t *testing.T) {
 
 	t.Run("unhandled_hook", func(t *testing.T) {
 		req := plugin.ExecuteRequest{
-			Hook:    plugin.HookPreInit,
+			Hook:    plugin.HookPreNotes,
 			Config:  baseConfig,
 			Context: baseContext,
 			DryRun:  true,
@@ -557,6 +561,142 @@ func TestExecute(t *testing.T) {
 	})
 }
 
+// TestLifecycleAnnouncements tests the opt-in start, plan and publishing announcements.
+func TestLifecycleAnnouncements(t *testing.T) {
+	p := &SlackPlugin{}
+	ctx := context.Background()
+
+	releaseCtx := plugin.ReleaseContext{
+		Version:         "1.3.0",
+		PreviousVersion: "1.2.0",
+		TagName:         "v1.3.0",
+		ReleaseType:     "minor",
+		RepositoryOwner: "acme",
+		RepositoryName:  "widgets",
+		Branch:          "main",
+		Changes: &plugin.CategorizedChanges{
+			Features: []plugin.ConventionalCommit{{Hash: "a", Type: "feat", Description: "Add <!channel> export"}},
+			Fixes:    []plugin.ConventionalCommit{{Hash: "b", Type: "fix", Description: "Fix crash"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		hook         plugin.Hook
+		toggle       string
+		config       map[string]any
+		wantDisabled string
+		wantMessage  string
+		wantText     []string
+	}{
+		{
+			name:         "start disabled by default",
+			hook:         plugin.HookPreInit,
+			wantDisabled: "Start notification disabled",
+		},
+		{
+			name:        "start",
+			hook:        plugin.HookPreInit,
+			toggle:      "notify_on_start",
+			wantMessage: "Would send Slack start notification",
+			wantText:    []string{"Release starting", "acme/widgets", "`main`"},
+		},
+		{
+			name:         "plan disabled by default",
+			hook:         plugin.HookPostPlan,
+			wantDisabled: "Plan notification disabled",
+		},
+		{
+			name:        "plan",
+			hook:        plugin.HookPostPlan,
+			toggle:      "notify_on_plan",
+			wantMessage: "Would send Slack plan notification",
+			wantText:    []string{"Release 1.3.0 planned", "(minor)", "following 1.2.0", "1 feature, 1 fix", "• Add &lt;!channel&gt; export", "• Fix crash"},
+		},
+		{
+			name:         "publishing disabled by default",
+			hook:         plugin.HookPrePublish,
+			wantDisabled: "Publishing notification disabled",
+		},
+		{
+			name:        "publishing",
+			hook:        plugin.HookPrePublish,
+			toggle:      "notify_on_publishing",
+			wantMessage: "Would send Slack publishing notification",
+			wantText:    []string{"Publishing release *1.3.0*", "`v1.3.0`"},
+		},
+		{
+			name:        "custom template with mentions",
+			hook:        plugin.HookPrePublish,
+			toggle:      "notify_on_publishing",
+			config:      map[string]any{"publishing_template": "Shipping {{.TagName}} <!here>", "mentions": []any{"U123"}},
+			wantMessage: "Would send Slack publishing notification",
+			wantText:    []string{"<@U123> Shipping v1.3.0 <!here>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{"webhook": "https://hooks.slack.com/services/T00/B00/XXX"}
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			if tt.toggle != "" {
+				config[tt.toggle] = true
+			}
+
+			resp, err := p.Execute(ctx, plugin.ExecuteRequest{
+				Hook:    tt.hook,
+				Config:  config,
+				Context: releaseCtx,
+				DryRun:  true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("expected success, got failure: %s", resp.Error)
+			}
+
+			if tt.wantDisabled != "" {
+				if resp.Message != tt.wantDisabled {
+					t.Errorf("expected %q, got %q", tt.wantDisabled, resp.Message)
+				}
+				return
+			}
+
+			if resp.Message != tt.wantMessage {
+				t.Errorf("expected %q, got %q", tt.wantMessage, resp.Message)
+			}
+			text, _ := resp.Outputs["text"].(string)
+			for _, want := range tt.wantText {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected text to contain %q, got %q", want, text)
+				}
+			}
+		})
+	}
+
+	t.Run("broken template fails the hook", func(t *testing.T) {
+		resp, err := p.Execute(ctx, plugin.ExecuteRequest{
+			Hook: plugin.HookPreInit,
+			Config: map[string]any{
+				"webhook":         "https://hooks.slack.com/services/T00/B00/XXX",
+				"notify_on_start": true,
+				"start_template":  "{{.Nope}}",
+			},
+			Context: releaseCtx,
+			DryRun:  true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Success || !strings.Contains(resp.Error, "failed to render start_template") {
+			t.Errorf("expected render failure, got %+v", resp)
+		}
+	})
+}
+
 // TestBuildSlackMentions tests the mention formatting function.
 func TestBuildSlackMentions(t *testing.T) {
 	tests := []struct {
@@ -599,6 +739,11 @@ func TestBuildSlackMentions(t *testing.T) {
 			mentions: []string{"U123", "@U456", "<@U789>"},
 			expected: "<@U123> <@U456> <@U789>",
 		},
+		{
+			name:     "broadcast mentions",
+			mentions: []string{"@channel", "@here", "@everyone"},
+			expected: "<!channel> <!here> <!everyone>",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1012,30 +1157,24 @@ func TestContextCancellation(t *testing.T) {
 	}
 }
 
-// TestSendMessageActual tests sending messages with an actual HTTP mock server.
+// testWebhookURL is a well-formed webhook URL; with a base URL set, the
+// plugin sends it to the fake Slack server.
+const testWebhookURL = "https://hooks.slack.com/services/T000/B000/XXXXXXXX"
+
+// newFakeSlack starts a fake Slack server and returns a plugin pointed at it.
+func newFakeSlack(t *testing.T) (*SlackPlugin, *slacktest.Server) {
+	t.Helper()
+	server := slacktest.NewServer()
+	t.Cleanup(server.Close)
+	return NewSlackPlugin(WithHTTPClient(server.Client()), WithBaseURL(server.URL), WithStateDir(t.TempDir()), WithoutRateLimits()), server
+}
+
+// TestSendMessageActual tests sending messages to a fake Slack server.
 func TestSendMessageActual(t *testing.T) {
-	p := &SlackPlugin{}
 	ctx := context.Background()
 
 	t.Run("successful send", func(t *testing.T) {
-		// Create a test server that accepts Slack webhook requests
-		var receivedMsg SlackMessage
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != "POST" {
-				t.Errorf("expected POST, got %s", r.Method)
-			}
-			if r.Header.Get("Content-Type") != "application/json" {
-				t.Errorf("expected Content-Type application/json, got %s", r.Header.Get("Content-Type"))
-			}
-
-			if err := json.NewDecoder(r.Body).Decode(&receivedMsg); err != nil {
-				t.Errorf("failed to decode request: %v", err)
-			}
-
-			w.WriteHeader(http.StatusOK)
-			_, _ = w.Write([]byte("ok"))
-		}))
-		defer server.Close()
+		p, server := newFakeSlack(t)
 
 		msg := SlackMessage{
 			Channel:  "#test",
@@ -1043,52 +1182,71 @@ func TestSendMessageActual(t *testing.T) {
 			Text:     "Test message",
 		}
 
-		// Create a custom HTTP client for testing (no TLS requirement)
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
-		err := p.sendMessage(ctx, server.URL, msg)
+		err := p.sendMessage(ctx, testWebhookURL, msg)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
-		if receivedMsg.Channel != "#test" {
-			t.Errorf("expected channel #test, got %s", receivedMsg.Channel)
+		calls := server.Calls(slacktest.Webhook)
+		if len(calls) != 1 {
+			t.Fatalf("expected one webhook request, got %d", len(calls))
+		}
+		if calls[0].Path != "/services/T000/B000/XXXXXXXX" {
+			t.Errorf("expected the webhook path to be kept, got %s", calls[0].Path)
+		}
+		if calls[0].Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected Content-Type application/json, got %s", calls[0].Header.Get("Content-Type"))
+		}
+		if calls[0].Body["channel"] != "#test" {
+			t.Errorf("expected channel #test, got %v", calls[0].Body["channel"])
 		}
-		if receivedMsg.Username != "TestBot" {
-			t.Errorf("expected username TestBot, got %s", receivedMsg.Username)
+		if calls[0].Body["username"] != "TestBot" {
+			t.Errorf("expected username TestBot, got %v", calls[0].Body["username"])
 		}
 	})
 
 	t.Run("server error", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusInternalServerError)
-		}))
-		defer server.Close()
+		p, server := newFakeSlack(t)
+		server.FailNext(slacktest.Webhook, slacktest.ServerError())
 
 		msg := SlackMessage{Text: "Test"}
 
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
-		err := p.sendMessage(ctx, server.URL, msg)
+		err := p.sendMessage(ctx, testWebhookURL, msg)
 		if err == nil {
-			t.Error("expected error for server error response")
+			t.Fatal("expected error for server error response")
 		}
 		if !strings.Contains(err.Error(), "status 500") {
 			t.Errorf("expected error to mention status 500, got: %v", err)
 		}
 	})
 
+	t.Run("invalid payload", func(t *testing.T) {
+		p, server := newFakeSlack(t)
+		server.FailNext(slacktest.Webhook, slacktest.InvalidPayload())
+
+		err := p.sendMessage(ctx, testWebhookURL, SlackMessage{Text: "Test"})
+		if err == nil || !strings.Contains(err.Error(), "status 400: invalid_payload") {
+			t.Errorf("expected Slack's error code, got: %v", err)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		p, server := newFakeSlack(t)
+		server.FailNext(slacktest.Webhook, slacktest.RateLimited(30*time.Second))
+
+		err := p.sendMessage(ctx, testWebhookURL, SlackMessage{Text: "Test"})
+		if err == nil || !strings.Contains(err.Error(), "status 429") {
+			t.Errorf("expected a rate limit error, got: %v", err)
+		}
+		if err := p.sendMessage(ctx, testWebhookURL, SlackMessage{Text: "Test"}); err != nil {
+			t.Errorf("expected the failure to apply once, got: %v", err)
+		}
+	})
+
 	t.Run("invalid URL", func(t *testing.T) {
+		p := &SlackPlugin{}
 		msg := SlackMessage{Text: "Test"}
 
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
 		err := p.sendMessage(ctx, "://invalid", msg)
 		if err == nil {
 			t.Error("expected error for invalid URL")
@@ -1096,191 +1254,94 @@ func TestSendMessageActual(t *testing.T) {
 	})
 
 	t.Run("context cancelled", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			time.Sleep(2 * time.Second)
-			w.WriteHeader(http.StatusOK)
-		}))
-		defer server.Close()
+		p, server := newFakeSlack(t)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
 		msg := SlackMessage{Text: "Test"}
 
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
-		err := p.sendMessage(ctx, server.URL, msg)
+		err := p.sendMessage(ctx, testWebhookURL, msg)
 		if err == nil {
 			t.Error("expected error for cancelled context")
 		}
+		if len(server.Requests()) != 0 {
+			t.Error("expected no request after cancellation")
+		}
 	})
 }
 
-// TestExecuteWithRealHTTP tests execute with actual HTTP responses.
+// TestExecuteWithRealHTTP tests execute against a fake Slack server.
 func TestExecuteWithRealHTTP(t *testing.T) {
-	p := &SlackPlugin{}
 	ctx := context.Background()
 
-	t.Run("success notification - HTTP OK", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-		}))
-		defer server.Close()
-
-		// Temporarily replace the HTTP client
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
-		config := map[string]any{
-			"webhook": server.URL,
-		}
-
-		releaseCtx := plugin.ReleaseContext{
-			Version:     "1.0.0",
-			TagName:     "v1.0.0",
-			ReleaseType: "patch",
-			Branch:      "main",
-		}
-
-		req := plugin.ExecuteRequest{
-			Hook:    plugin.HookPostPublish,
-			Config:  config,
-			Context: releaseCtx,
-			DryRun:  false, // Actually send
-		}
-
-		resp, err := p.Execute(ctx, req)
+	execute := func(t *testing.T, p *SlackPlugin, hook plugin.Hook) *plugin.ExecuteResponse {
+		t.Helper()
+		resp, err := p.Execute(ctx, plugin.ExecuteRequest{
+			Hook:   hook,
+			Config: map[string]any{"webhook": testWebhookURL},
+			Context: plugin.ReleaseContext{
+				Version:     "1.0.0",
+				TagName:     "v1.0.0",
+				ReleaseType: "patch",
+				Branch:      "main",
+			},
+			DryRun: false, // Actually send
+		})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
+		return resp
+	}
+
+	t.Run("success notification - HTTP OK", func(t *testing.T) {
+		p, server := newFakeSlack(t)
 
+		resp := execute(t, p, plugin.HookPostPublish)
 		if !resp.Success {
 			t.Errorf("expected success, got failure: %s", resp.Error)
 		}
-
 		if !strings.Contains(resp.Message, "Sent Slack success notification") {
 			t.Errorf("unexpected message: %s", resp.Message)
 		}
+		if len(server.Calls(slacktest.Webhook)) != 1 {
+			t.Errorf("expected one webhook request, got %v", server.Requests())
+		}
 	})
 
 	t.Run("success notification - HTTP error", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusBadRequest)
-		}))
-		defer server.Close()
-
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
-		config := map[string]any{
-			"webhook": server.URL,
-		}
-
-		releaseCtx := plugin.ReleaseContext{
-			Version:     "1.0.0",
-			TagName:     "v1.0.0",
-			ReleaseType: "patch",
-			Branch:      "main",
-		}
-
-		req := plugin.ExecuteRequest{
-			Hook:    plugin.HookPostPublish,
-			Config:  config,
-			Context: releaseCtx,
-			DryRun:  false,
-		}
-
-		resp, err := p.Execute(ctx, req)
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
+		p, server := newFakeSlack(t)
+		server.FailNext(slacktest.Webhook, slacktest.InvalidPayload())
 
+		resp := execute(t, p, plugin.HookPostPublish)
 		if resp.Success {
 			t.Error("expected failure, got success")
 		}
-
 		if !strings.Contains(resp.Error, "failed to send Slack message") {
 			t.Errorf("unexpected error: %s", resp.Error)
 		}
+		if strings.Contains(resp.Error, "XXXXXXXX") {
+			t.Errorf("expected the webhook to be redacted: %s", resp.Error)
+		}
 	})
 
 	t.Run("error notification - HTTP OK", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-		}))
-		defer server.Close()
-
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
-		config := map[string]any{
-			"webhook": server.URL,
-		}
-
-		releaseCtx := plugin.ReleaseContext{
-			Version: "1.0.0",
-			TagName: "v1.0.0",
-			Branch:  "main",
-		}
-
-		req := plugin.ExecuteRequest{
-			Hook:    plugin.HookOnError,
-			Config:  config,
-			Context: releaseCtx,
-			DryRun:  false,
-		}
-
-		resp, err := p.Execute(ctx, req)
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
+		p, _ := newFakeSlack(t)
 
+		resp := execute(t, p, plugin.HookOnError)
 		if !resp.Success {
 			t.Errorf("expected success, got failure: %s", resp.Error)
 		}
-
 		if !strings.Contains(resp.Message, "Sent Slack error notification") {
 			t.Errorf("unexpected message: %s", resp.Message)
 		}
 	})
 
 	t.Run("error notification - HTTP error", func(t *testing.T) {
-		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusServiceUnavailable)
-		}))
-		defer server.Close()
-
-		originalClient := defaultHTTPClient
-		defaultHTTPClient = &http.Client{Timeout: 5 * time.Second}
-		defer func() { defaultHTTPClient = originalClient }()
-
-		config := map[string]any{
-			"webhook": server.URL,
-		}
-
-		releaseCtx := plugin.ReleaseContext{
-			Version: "1.0.0",
-			TagName: "v1.0.0",
-			Branch:  "main",
-		}
-
-		req := plugin.ExecuteRequest{
-			Hook:    plugin.HookOnError,
-			Config:  config,
-			Context: releaseCtx,
-			DryRun:  false,
-		}
-
-		resp, err := p.Execute(ctx, req)
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
+		p, server := newFakeSlack(t)
+		server.FailNext(slacktest.Webhook, slacktest.Failure{Status: http.StatusServiceUnavailable})
 
+		resp := execute(t, p, plugin.HookOnError)
 		if resp.Success {
 			t.Error("expected failure, got success")
 		}
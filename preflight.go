@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// authTestResponse is the response of auth.test.
+type authTestResponse struct {
+	apiResponse
+	Team   string `json:"team"`
+	User   string `json:"user"`
+	UserID string `json:"user_id"`
+	BotID  string `json:"bot_id"`
+}
+
+// conversationsInfoResponse is the response of conversations.info.
+type conversationsInfoResponse struct {
+	apiResponse
+	Channel struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		IsPrivate  bool   `json:"is_private"`
+		IsArchived bool   `json:"is_archived"`
+		IsMember   bool   `json:"is_member"`
+		Topic      struct {
+			Value string `json:"value"`
+		} `json:"topic"`
+	} `json:"channel"`
+}
+
+// preflightText is posted to the sandbox channel when webhook preflight posting is enabled.
+const preflightText = ":white_check_mark: Relicta preflight check: this webhook can post release notifications."
+
+// preflight checks that the configured destination is reachable and authorized.
+// Hard failures are added to vb and advisory findings to warnings.
+func (p *SlackPlugin) preflight(ctx context.Context, cfg *Config, vb, warnings *helpers.ValidationBuilder) {
+	if cfg.BotToken != "" {
+		p.preflightBotToken(ctx, cfg, vb, warnings)
+		return
+	}
+	p.preflightWebhook(ctx, cfg, vb, warnings)
+}
+
+// preflightBotToken verifies the token, its scopes and channel membership.
+func (p *SlackPlugin) preflightBotToken(ctx context.Context, cfg *Config, vb, warnings *helpers.ValidationBuilder) {
+	var auth authTestResponse
+	headers, err := p.callAPI(ctx, cfg.BotToken, "auth.test", nil, &auth)
+	if err != nil {
+		vb.AddErrorWithCode("bot_token", fmt.Sprintf("preflight failed: %v", err), "unauthorized")
+		return
+	}
+
+	// Tokens that do not report scopes (e.g. legacy tokens) skip the scope checks.
+	scopes := headers.Get("X-OAuth-Scopes")
+	if scopes != "" {
+		if !hasScope(scopes, "chat:write") {
+			vb.AddErrorWithCode("bot_token", "bot token is missing the chat:write scope", "missing_scope")
+		}
+		if cfg.customizesIdentity() && !hasScope(scopes, "chat:write.customize") {
+			warnings.AddErrorWithCode("bot_token",
+				"bot token is missing the chat:write.customize scope; username and icon settings will be ignored",
+				warningCodePrefix+"missing_scope")
+		}
+		if cfg.Reactions && !hasScope(scopes, "reactions:write") {
+			warnings.AddErrorWithCode("reactions",
+				"bot token is missing the reactions:write scope; release state reactions will fail",
+				warningCodePrefix+"missing_scope")
+		}
+		if (cfg.UploadReleaseNotes || len(cfg.Artifacts) > 0) && !hasScope(scopes, "files:write") {
+			warnings.AddErrorWithCode("upload_release_notes",
+				"bot token is missing the files:write scope; release files will not be uploaded",
+				warningCodePrefix+"missing_scope")
+		}
+		if cfg.ChannelTopicTemplate != "" && !hasScope(scopes, "channels:manage") && !hasScope(scopes, "groups:write") {
+			warnings.AddErrorWithCode("channel_topic_template",
+				"bot token is missing the channels:manage (or groups:write) scope; the channel topic will not be updated",
+				warningCodePrefix+"missing_scope")
+		}
+		if cfg.ChannelBookmarkTemplate != "" && !hasScope(scopes, "bookmarks:write") {
+			warnings.AddErrorWithCode("channel_bookmark_template",
+				"bot token is missing the bookmarks:write scope; the release bookmark will not be updated",
+				warningCodePrefix+"missing_scope")
+		}
+	}
+
+	if !channelIDPattern.MatchString(cfg.Channel) {
+		warnings.AddErrorWithCode("channel",
+			"channel membership can only be verified when channel is a channel ID (C…)",
+			warningCodePrefix+"preflight")
+		return
+	}
+
+	var info conversationsInfoResponse
+	if _, err := p.callAPI(ctx, cfg.BotToken, "conversations.info", url.Values{"channel": {cfg.Channel}}, &info); err != nil {
+		if isSlackError(err, "channel_not_found") {
+			vb.AddErrorWithCode("channel", fmt.Sprintf("channel %s was not found or is not visible to the bot", cfg.Channel), "channel_not_found")
+			return
+		}
+		if isSlackError(err, "missing_scope") {
+			warnings.AddErrorWithCode("channel",
+				fmt.Sprintf("cannot verify channel membership: %v", err),
+				warningCodePrefix+"missing_scope")
+			return
+		}
+		vb.AddErrorWithCode("channel", fmt.Sprintf("preflight failed: %v", err), "unreachable")
+		return
+	}
+
+	switch {
+	case info.Channel.IsArchived:
+		vb.AddErrorWithCode("channel", fmt.Sprintf("channel %s is archived", cfg.Channel), "channel_archived")
+	case !info.Channel.IsMember && (info.Channel.IsPrivate || !hasScope(scopes, "chat:write.public")):
+		vb.AddErrorWithCode("channel",
+			fmt.Sprintf("bot is not a member of channel %s; invite it with /invite", cfg.Channel),
+			"not_in_channel")
+	}
+}
+
+// preflightWebhook verifies that the webhook still exists.
+// With preflight_channel set it posts a visible test message to that sandbox
+// channel; otherwise it sends an empty payload, which live webhooks reject with
+// 400 while revoked or deleted webhooks answer 403, 404 or 410.
+func (p *SlackPlugin) preflightWebhook(ctx context.Context, cfg *Config, vb, warnings *helpers.ValidationBuilder) {
+	if cfg.PreflightChannel != "" {
+		msg := SlackMessage{
+			Channel:   cfg.PreflightChannel,
+			Username:  cfg.Username,
+			IconEmoji: cfg.IconEmoji,
+			IconURL:   cfg.IconURL,
+			Text:      preflightText,
+		}
+		if err := p.sendMessage(ctx, cfg.WebhookURL, msg); err != nil {
+			vb.AddErrorWithCode("webhook", fmt.Sprintf("preflight test message failed: %v", err), "unreachable")
+		}
+		return
+	}
+
+	if err := p.rateLimiter().wait(ctx, webhookLimits(cfg.WebhookURL)); err != nil {
+		vb.AddErrorWithCode("webhook", fmt.Sprintf("preflight failed: %v", err), "unreachable")
+		return
+	}
+	_, status, body, err := p.postWebhook(ctx, cfg.WebhookURL, []byte("{}"))
+	if err != nil {
+		vb.AddErrorWithCode("webhook", fmt.Sprintf("preflight failed: %v", err), "unreachable")
+		return
+	}
+
+	switch status {
+	case http.StatusBadRequest, http.StatusOK:
+		// The webhook exists and parsed the request.
+	case http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		vb.AddErrorWithCode("webhook",
+			fmt.Sprintf("webhook is revoked or deleted (status %d: %s)", status, body),
+			"unreachable")
+	default:
+		warnings.AddErrorWithCode("webhook",
+			fmt.Sprintf("unexpected preflight response (status %d: %s)", status, body),
+			warningCodePrefix+"preflight")
+	}
+}
+
+// customizesIdentity reports whether messages override the bot's name or icon.
+func (c *Config) customizesIdentity() bool {
+	return c.Username != "" || c.IconEmoji != "" || c.IconURL != ""
+}
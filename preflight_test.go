@@ -0,0 +1,241 @@
+// Package main provides tests for preflight connectivity checks.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// findError returns the validation entry for field, if any.
+func findError(resp *plugin.ValidateResponse, field string) *plugin.ValidationError {
+	for i := range resp.Errors {
+		if resp.Errors[i].Field == field {
+			return &resp.Errors[i]
+		}
+	}
+	return nil
+}
+
+// TestPreflightBotToken tests auth, scope and membership checks in bot-token mode.
+func TestPreflightBotToken(t *testing.T) {
+	p := &SlackPlugin{}
+	ctx := context.Background()
+	_ = os.Unsetenv("SLACK_WEBHOOK_URL")
+
+	tests := []struct {
+		name      string
+		scopes    string
+		authError string
+		channel   map[string]any
+		infoError string
+		wantValid bool
+		wantField string
+		wantCode  string
+	}{
+		{
+			name:      "all good",
+			scopes:    "chat:write,chat:write.customize",
+			channel:   map[string]any{"id": "C0123456789", "is_member": true},
+			wantValid: true,
+		},
+		{
+			name:      "revoked token",
+			authError: "token_revoked",
+			wantField: "bot_token",
+			wantCode:  "unauthorized",
+		},
+		{
+			name:      "missing chat:write",
+			scopes:    "channels:read",
+			channel:   map[string]any{"id": "C0123456789", "is_member": true},
+			wantField: "bot_token",
+			wantCode:  "missing_scope",
+		},
+		{
+			name:      "missing chat:write.customize is a warning",
+			scopes:    "chat:write",
+			channel:   map[string]any{"id": "C0123456789", "is_member": true},
+			wantValid: true,
+			wantField: "bot_token",
+			wantCode:  warningCodePrefix + "missing_scope",
+		},
+		{
+			name:      "not in channel",
+			scopes:    "chat:write,chat:write.customize",
+			channel:   map[string]any{"id": "C0123456789", "is_member": false},
+			wantField: "channel",
+			wantCode:  "not_in_channel",
+		},
+		{
+			name:      "public channel with chat:write.public",
+			scopes:    "chat:write,chat:write.customize,chat:write.public",
+			channel:   map[string]any{"id": "C0123456789", "is_member": false},
+			wantValid: true,
+		},
+		{
+			name:      "archived channel",
+			scopes:    "chat:write,chat:write.customize",
+			channel:   map[string]any{"id": "C0123456789", "is_member": true, "is_archived": true},
+			wantField: "channel",
+			wantCode:  "channel_archived",
+		},
+		{
+			name:      "channel not found",
+			scopes:    "chat:write,chat:write.customize",
+			infoError: "channel_not_found",
+			wantField: "channel",
+			wantCode:  "channel_not_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/auth.test":
+					if tt.authError != "" {
+						writeJSON(w, map[string]any{"ok": false, "error": tt.authError})
+						return
+					}
+					w.Header().Set("X-OAuth-Scopes", tt.scopes)
+					writeJSON(w, map[string]any{"ok": true, "team": "Acme", "user_id": "U1"})
+				case "/api/conversations.info":
+					if tt.infoError != "" {
+						writeJSON(w, map[string]any{"ok": false, "error": tt.infoError})
+						return
+					}
+					writeJSON(w, map[string]any{"ok": true, "channel": tt.channel})
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+			}))
+
+			resp, err := p.Validate(ctx, map[string]any{
+				"bot_token": "xoxb-1-2-3",
+				"channel":   "C0123456789",
+				"preflight": true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Valid != tt.wantValid {
+				t.Errorf("expected valid=%v, got %v (%v)", tt.wantValid, resp.Valid, resp.Errors)
+			}
+			if tt.wantField == "" {
+				if len(resp.Errors) > 0 {
+					t.Errorf("expected no findings, got %v", resp.Errors)
+				}
+				return
+			}
+			e := findError(resp, tt.wantField)
+			if e == nil {
+				t.Fatalf("expected finding for %q, got %v", tt.wantField, resp.Errors)
+			}
+			if e.Code != tt.wantCode {
+				t.Errorf("expected code %q, got %q", tt.wantCode, e.Code)
+			}
+		})
+	}
+
+	t.Run("channel name cannot be verified", func(t *testing.T) {
+		useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-OAuth-Scopes", "chat:write,chat:write.customize")
+			writeJSON(w, map[string]any{"ok": true})
+		}))
+
+		resp, err := p.Validate(ctx, map[string]any{"bot_token": "xoxb-1-2-3", "channel": "#releases", "preflight": true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Valid {
+			t.Errorf("expected valid config, got %v", resp.Errors)
+		}
+		if e := findError(resp, "channel"); e == nil || e.Code != warningCodePrefix+"preflight" {
+			t.Errorf("expected preflight warning for channel, got %v", resp.Errors)
+		}
+	})
+}
+
+// TestPreflightWebhook tests webhook liveness probing and sandbox test posts.
+func TestPreflightWebhook(t *testing.T) {
+	p := &SlackPlugin{}
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantValid bool
+		wantCode  string
+	}{
+		{name: "live webhook", status: http.StatusBadRequest, body: "no_text", wantValid: true},
+		{name: "revoked webhook", status: http.StatusForbidden, body: "invalid_token", wantCode: "unreachable"},
+		{name: "deleted webhook", status: http.StatusNotFound, body: "no_service", wantCode: "unreachable"},
+		{name: "unexpected status", status: http.StatusTeapot, wantValid: true, wantCode: warningCodePrefix + "preflight"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+
+			cfg := p.parseConfig(map[string]any{"webhook": server.URL})
+			vb, warnings := helpers.NewValidationBuilder(), helpers.NewValidationBuilder()
+			p.preflight(context.Background(), cfg, vb, warnings)
+			resp := mergeWarnings(vb.Build(), warnings)
+
+			if resp.Valid != tt.wantValid {
+				t.Errorf("expected valid=%v, got %v (%v)", tt.wantValid, resp.Valid, resp.Errors)
+			}
+			if tt.wantCode != "" {
+				e := findError(resp, "webhook")
+				if e == nil || e.Code != tt.wantCode {
+					t.Fatalf("expected code %q, got %v", tt.wantCode, resp.Errors)
+				}
+				if tt.body != "" && !strings.Contains(e.Message, tt.body) {
+					t.Errorf("expected message to contain Slack error %q, got %q", tt.body, e.Message)
+				}
+			}
+		})
+	}
+
+	t.Run("sandbox test message", func(t *testing.T) {
+		var received SlackMessage
+		server := useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		cfg := p.parseConfig(map[string]any{"webhook": server.URL, "preflight_channel": "#sandbox"})
+		vb, warnings := helpers.NewValidationBuilder(), helpers.NewValidationBuilder()
+		p.preflight(context.Background(), cfg, vb, warnings)
+
+		if vb.HasErrors() {
+			t.Errorf("expected no errors, got %v", vb.Build().Errors)
+		}
+		if received.Channel != "#sandbox" || received.Text != preflightText {
+			t.Errorf("unexpected test message %+v", received)
+		}
+	})
+
+	t.Run("preflight is opt-in", func(t *testing.T) {
+		resp, err := p.Validate(context.Background(), map[string]any{
+			"webhook": "https://hooks.slack.com/services/T00000000/B00000000/TESTTOKEN",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.Valid {
+			t.Errorf("expected valid config without network access, got %v", resp.Errors)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// blockKitBuilderURL renders the payload passed in the URL fragment.
+const blockKitBuilderURL = "https://app.slack.com/block-kit-builder/#"
+
+// mrkdwnReplacements turn Slack control sequences into readable text, in order.
+var mrkdwnReplacements = []struct {
+	pattern *regexp.Regexp
+	repl    string
+}{
+	{regexp.MustCompile(`<!(here|channel|everyone)>`), "@$1"},
+	{regexp.MustCompile(`<!subteam\^[A-Z0-9]+\|([^>]+)>`), "$1"},
+	{regexp.MustCompile(`<!subteam\^([A-Z0-9]+)>`), "@$1"},
+	{regexp.MustCompile(`<!date\^[0-9]+\^[^|>]*\|([^>]+)>`), "$1"},
+	{regexp.MustCompile(`<@([A-Z0-9]+)>`), "@$1"},
+	{regexp.MustCompile(`<([^|>]+)\|([^>]+)>`), "$2 ($1)"},
+	{regexp.MustCompile(`<([^>]+)>`), "$1"},
+}
+
+// messagePreview is the rendering of a message for one destination.
+type messagePreview struct {
+	// Destination is the channel, or "webhook" for the webhook's default channel.
+	Destination string `json:"destination"`
+	// Payload is the exact JSON body that would be sent.
+	Payload SlackMessage `json:"payload"`
+	// Text is a plain-text rendering of the message.
+	Text string `json:"text"`
+	// BuilderURL opens the message in Slack's Block Kit Builder.
+	BuilderURL string `json:"builder_url"`
+	// Fixes lists the repairs made to keep the payload within Slack's limits.
+	Fixes []string `json:"fixes,omitempty"`
+}
+
+// newMessagePreview renders msg as it would be delivered to target.
+func newMessagePreview(target *Config, msg SlackMessage) messagePreview {
+	return messagePreview{
+		Destination: target.destinationName(),
+		Payload:     msg,
+		Text:        plainTextRendering(msg),
+		BuilderURL:  builderURL(msg),
+	}
+}
+
+// previewMessages renders the message built for each target, checked
+// against Slack's limits as it would be before sending.
+func previewMessages(cfg *Config, build func(target *Config) (SlackMessage, error)) ([]messagePreview, error) {
+	var previews []messagePreview
+	for i, target := range cfg.targets() {
+		msg, err := build(target)
+		var fixes []string
+		if err == nil {
+			msg, fixes, err = target.preparePayload(msg)
+		}
+		if err != nil {
+			if i > 0 {
+				err = fmt.Errorf("destinations[%d]: %w", i-1, err)
+			}
+			return nil, err
+		}
+		preview := newMessagePreview(target, msg)
+		preview.Fixes = fixes
+		previews = append(previews, preview)
+	}
+	return previews, nil
+}
+
+// builderURL returns a Block Kit Builder link showing msg.
+func builderURL(msg SlackMessage) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(struct {
+		Text        string       `json:"text,omitempty"`
+		Blocks      []any        `json:"blocks,omitempty"`
+		Attachments []Attachment `json:"attachments,omitempty"`
+	}{msg.Text, msg.Blocks, msg.Attachments})
+	if err != nil {
+		return ""
+	}
+	return blockKitBuilderURL + url.PathEscape(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// plainTextRendering renders a message as readable plain text, one element per line.
+func plainTextRendering(msg SlackMessage) string {
+	var lines []string
+	add := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			lines = append(lines, readableMrkdwn(s))
+		}
+	}
+
+	add(msg.Text)
+	renderBlocks(msg.Blocks, add)
+	for _, att := range msg.Attachments {
+		if len(att.Blocks) > 0 {
+			renderBlocks(att.Blocks, add)
+			continue
+		}
+		add(att.Title)
+		add(att.Text)
+		for _, f := range att.Fields {
+			add(f.Title + ": " + f.Value)
+		}
+		var labels []string
+		for _, a := range att.Actions {
+			labels = append(labels, "["+a.Text+"]")
+		}
+		add(strings.Join(labels, " "))
+		add(att.Footer)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderBlocks passes the text of each block to add.
+func renderBlocks(blocks []any, add func(string)) {
+	for _, b := range blocks {
+		block, ok := b.(Block)
+		if !ok {
+			continue
+		}
+		if block.Label != nil {
+			add(block.Label.Text + ":")
+		}
+		if block.Text != nil {
+			add(block.Text.Text)
+		}
+		for _, f := range block.Fields {
+			add(strings.Replace(f.Text, "\n", " ", 1))
+		}
+		var labels []string
+		for _, e := range block.Elements {
+			switch el := e.(type) {
+			case *TextObject:
+				add(el.Text)
+			case ButtonElement:
+				labels = append(labels, "["+el.Text.Text+"]")
+			}
+		}
+		add(strings.Join(labels, " "))
+	}
+}
+
+// readableMrkdwn replaces mentions, links and dates with readable text.
+func readableMrkdwn(s string) string {
+	for _, r := range mrkdwnReplacements {
+		s = r.pattern.ReplaceAllString(s, r.repl)
+	}
+	return html.UnescapeString(s)
+}
+
+// previewOutputs adds the previews to dry-run outputs, as plain JSON values so
+// they can be redacted, and writes them to preview_file when configured.
+// kind keys the previews in the file, e.g. "success".
+func (c *Config) previewOutputs(outputs map[string]any, kind string, previews []messagePreview) error {
+	data, err := json.Marshal(previews)
+	if err != nil {
+		return fmt.Errorf("failed to encode preview: %w", err)
+	}
+	var value []any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("failed to encode preview: %w", err)
+	}
+	outputs["previews"] = value
+
+	if c.PreviewFile == "" {
+		return nil
+	}
+	if err := writePreviewFile(c.PreviewFile, kind, value, newRedactor(c.secrets()...)); err != nil {
+		return err
+	}
+	outputs["preview_file"] = c.PreviewFile
+	return nil
+}
+
+// writePreviewFile stores previews under kind in a JSON file, keeping the
+// previews of other kinds, so one dry run of the pipeline fills one file.
+func writePreviewFile(path, kind string, previews []any, r *redactor) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	all := map[string]any{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read preview file: %w", err)
+	default:
+		// An unreadable file is replaced.
+		_ = json.Unmarshal(data, &all)
+	}
+	all[kind] = r.Value(previews)
+
+	data, err = json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preview: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to write preview file: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o640); err != nil {
+		return fmt.Errorf("failed to write preview file: %w", err)
+	}
+	return nil
+}
+
+// previewResponse is the dry-run response for a notification: the given
+// outputs plus a preview of the message for every destination.
+func (p *SlackPlugin) previewResponse(cfg *Config, kind, message string, outputs map[string]any, build func(target *Config) (SlackMessage, error)) *plugin.ExecuteResponse {
+	previews, err := previewMessages(cfg, build)
+	if err == nil {
+		err = cfg.previewOutputs(outputs, kind, previews)
+	}
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: message,
+		Outputs: outputs,
+	}
+}
@@ -0,0 +1,128 @@
+// Package main provides tests for dry-run previews.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestPlainTextRendering tests the plain-text rendering of attachments and blocks.
+func TestPlainTextRendering(t *testing.T) {
+	msg := SlackMessage{
+		Text: "<!here> <@U123>",
+		Attachments: []Attachment{{
+			Title:  ":rocket: Release 1.2.0 Published!",
+			Text:   "Fixed &lt;script&gt; in <https://example.com/x|the docs>",
+			Fields: []Field{{Title: "Version", Value: "1.2.0"}},
+			Footer: "Relicta",
+		}},
+	}
+	want := "@here @U123\n:rocket: Release 1.2.0 Published!\nFixed <script> in the docs (https://example.com/x)\nVersion: 1.2.0\nRelicta"
+	if got := plainTextRendering(msg); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	msg = buildApprovalMessage(&Config{}, "r1", plugin.ReleaseContext{Version: "1.2.0"})
+	got := plainTextRendering(msg)
+	for _, part := range []string{"Approval required: 1.2.0", "Reason:", "[Approve] [Reject]"} {
+		if !strings.Contains(got, part) {
+			t.Errorf("expected %q in %q", part, got)
+		}
+	}
+
+	msg = SlackMessage{Attachments: []Attachment{{Blocks: attachmentBlocks(newPrinter(""), Attachment{
+		Title:  "Title",
+		Fields: []Field{{Title: "Branch", Value: "main", Short: true}},
+		Footer: "Relicta",
+		Ts:     1709647620,
+	})}}}
+	if got := plainTextRendering(msg); got != "Title\n*Branch* main\nRelicta | Mar 5, 2024 14:07 UTC" {
+		t.Errorf("unexpected blocks rendering %q", got)
+	}
+}
+
+// TestBuilderURL tests that the Block Kit Builder link carries the payload.
+func TestBuilderURL(t *testing.T) {
+	msg := SlackMessage{Channel: "#releases", Text: "hi & bye", Blocks: []any{Block{Type: "divider"}}}
+	link := builderURL(msg)
+	if !strings.HasPrefix(link, blockKitBuilderURL) {
+		t.Fatalf("unexpected link %q", link)
+	}
+	raw, err := url.PathUnescape(strings.TrimPrefix(link, blockKitBuilderURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw != `{"text":"hi & bye","blocks":[{"type":"divider"}]}` {
+		t.Errorf("unexpected payload %s", raw)
+	}
+}
+
+// TestDryRunPreview tests previews for every destination and the preview file.
+func TestDryRunPreview(t *testing.T) {
+	const secondWebhook = "https://hooks.slack.com/services/T1/B2/secondsecret"
+	file := filepath.Join(t.TempDir(), "review", "slack-preview.json")
+	config := map[string]any{
+		"webhook":      "https://hooks.slack.com/services/T1/B1/firstsecret",
+		"channel":      "#releases",
+		"preview_file": file,
+		"destinations": []any{map[string]any{"webhook": secondWebhook, "locale": "de"}},
+	}
+	execute := func(hook plugin.Hook) *plugin.ExecuteResponse {
+		t.Helper()
+		resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    hook,
+			Config:  config,
+			Context: plugin.ReleaseContext{Version: "1.2.0", ReleaseType: "minor"},
+			DryRun:  true,
+		})
+		if err != nil || !resp.Success {
+			t.Fatalf("expected success, got %v / %+v", err, resp)
+		}
+		return resp
+	}
+
+	resp := execute(plugin.HookOnSuccess)
+	previews, _ := resp.Outputs["previews"].([]any)
+	if len(previews) != 2 {
+		t.Fatalf("expected a preview per destination, got %v", resp.Outputs)
+	}
+	primary := previews[0].(map[string]any)
+	second := previews[1].(map[string]any)
+	if primary["destination"] != "#releases" || second["destination"] != "#releases" {
+		t.Errorf("unexpected destinations %v / %v", primary["destination"], second["destination"])
+	}
+	if !strings.Contains(second["text"].(string), "Release 1.2.0 veröffentlicht!") {
+		t.Errorf("expected the destination's locale, got %q", second["text"])
+	}
+	payload := primary["payload"].(map[string]any)
+	if payload["channel"] != "#releases" || payload["attachments"] == nil {
+		t.Errorf("expected the exact payload, got %v", payload)
+	}
+	if !strings.HasPrefix(primary["builder_url"].(string), blockKitBuilderURL) {
+		t.Errorf("unexpected builder URL %v", primary["builder_url"])
+	}
+
+	execute(plugin.HookOnError)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("expected secrets to be redacted from the preview file:\n%s", data)
+	}
+	var all map[string][]messagePreview
+	if err := json.Unmarshal(data, &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all["success"]) != 2 || len(all["error"]) != 2 {
+		t.Errorf("expected success and error previews in one file, got %v", all)
+	}
+}
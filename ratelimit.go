@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Slack's Web API rate limit tiers, in calls per minute per workspace.
+const (
+	tier2 = 20
+	tier3 = 50
+	tier4 = 100
+)
+
+// methodTiers maps the Web API methods the plugin calls to their tier.
+// chat.postMessage has no tier: it is limited per channel instead.
+var methodTiers = map[string]int{
+	"auth.test":                    tier4,
+	"bookmarks.add":                tier2,
+	"bookmarks.edit":               tier2,
+	"bookmarks.list":               tier2,
+	"chat.getPermalink":            tier4,
+	"chat.scheduleMessage":         tier3,
+	"conversations.info":           tier3,
+	"conversations.setTopic":       tier2,
+	"files.completeUploadExternal": tier4,
+	"files.getUploadURLExternal":   tier4,
+	"reactions.add":                tier3,
+	"reactions.remove":             tier2,
+}
+
+// postingMethods post to a channel and share its one message per second.
+var postingMethods = map[string]bool{
+	"chat.postMessage":     true,
+	"chat.scheduleMessage": true,
+}
+
+// Retries of rate-limited requests.
+const (
+	// maxRateLimitAttempts is how often a request answered with 429 is sent.
+	maxRateLimitAttempts = 3
+	// maxRetryAfter is the longest Retry-After a request waits for; Slack
+	// asking for a longer pause fails the request instead of stalling the release.
+	maxRetryAfter = 10 * time.Second
+	// defaultRetryAfter is used when a 429 carries no Retry-After header.
+	defaultRetryAfter = time.Second
+)
+
+// rateLimit is a token bucket: rate tokens per second, holding at most burst.
+type rateLimit struct {
+	rate  float64
+	burst float64
+}
+
+var (
+	// channelLimit is Slack's limit of about one message per second per
+	// channel, which also applies to incoming webhooks.
+	channelLimit = rateLimit{rate: 1, burst: 1}
+	// uploadLimit paces file content uploads like the upload methods.
+	uploadLimit = tierLimit(tier4)
+)
+
+// tierLimit allows calls per minute, all of which may come in a burst.
+func tierLimit(perMinute int) rateLimit {
+	return rateLimit{rate: float64(perMinute) / 60, burst: float64(perMinute)}
+}
+
+// limitKey names a bucket and its limit.
+type limitKey struct {
+	name  string
+	limit rateLimit
+}
+
+// apiLimits returns the buckets a Web API call draws from: its channel for
+// posting methods, and its method tier. Unknown methods are paced as tier 3.
+func apiLimits(method string, body any) []limitKey {
+	var keys []limitKey
+	if postingMethods[method] {
+		if channel := requestChannel(body); channel != "" {
+			keys = append(keys, limitKey{name: "channel:" + channel, limit: channelLimit})
+		}
+	}
+	if method == "chat.postMessage" {
+		return keys
+	}
+	tier, ok := methodTiers[method]
+	if !ok {
+		tier = tier3
+	}
+	return append(keys, limitKey{name: "method:" + method, limit: tierLimit(tier)})
+}
+
+// webhookLimits returns the bucket of a webhook.
+func webhookLimits(webhookURL string) []limitKey {
+	return []limitKey{{name: "webhook:" + webhookURL, limit: channelLimit}}
+}
+
+// requestChannel returns the channel a Web API request posts to.
+func requestChannel(body any) string {
+	switch b := body.(type) {
+	case SlackMessage:
+		return b.Channel
+	case scheduledMessage:
+		return b.Channel
+	case url.Values:
+		return b.Get("channel")
+	}
+	return ""
+}
+
+// retryAfter returns how long Slack asked to wait before retrying a 429
+// response, and whether the request should be retried at all.
+func retryAfter(status int, header http.Header) (time.Duration, bool) {
+	if status != http.StatusTooManyRequests {
+		return 0, false
+	}
+	d := defaultRetryAfter
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		d = time.Duration(seconds) * time.Second
+	}
+	return d, d <= maxRetryAfter
+}
+
+// rateLimiter paces outbound requests with token buckets shared by every
+// send of a plugin. Requests over the limit queue in arrival order instead
+// of failing.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	// unlimited does not pace requests; they only wait while Slack asked
+	// for a pause with Retry-After.
+	unlimited bool
+	// now and after are the clock; nil uses the time package.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// tokenBucket is the state of one bucket. tokens goes negative while
+// requests are queued: each waits until its token has been refilled.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	// pausedUntil is when a Retry-After ends.
+	pausedUntil time.Time
+}
+
+// defaultRateLimiter is shared by plugins created without NewSlackPlugin.
+var defaultRateLimiter = newRateLimiter()
+
+// newRateLimiter returns an empty rate limiter on the system clock.
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: map[string]*tokenBucket{}}
+}
+
+// rateLimiter returns the plugin's rate limiter.
+func (p *SlackPlugin) rateLimiter() *rateLimiter {
+	if p.limiter != nil {
+		return p.limiter
+	}
+	return defaultRateLimiter
+}
+
+// wait blocks until every bucket in keys has a token for the request, or
+// ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, keys []limitKey) error {
+	for i, k := range keys {
+		if err := l.take(ctx, k); err != nil {
+			if !l.unlimited {
+				// Hand back the tokens taken for the buckets before.
+				for _, taken := range keys[:i] {
+					l.refund(taken)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// take reserves a token of one bucket and waits until it is due and the
+// bucket is not paused, which a 429 may have done while waiting.
+func (l *rateLimiter) take(ctx context.Context, k limitKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	b := l.bucket(k)
+	var delay time.Duration
+	if !l.unlimited {
+		b.tokens--
+		delay = time.Duration(-b.tokens / k.limit.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	cancel := func() error {
+		if !l.unlimited {
+			l.refund(k)
+		}
+		return ctx.Err()
+	}
+	for {
+		l.mu.Lock()
+		delay = max(delay, b.pausedUntil.Sub(l.clock()))
+		l.mu.Unlock()
+		if delay <= 0 {
+			if ctx.Err() != nil {
+				return cancel()
+			}
+			return nil
+		}
+		select {
+		case <-l.timer(delay):
+			delay = 0
+		case <-ctx.Done():
+			return cancel()
+		}
+	}
+}
+
+// pause holds the buckets in keys for d and empties them, after Slack
+// answered a request with 429.
+func (l *rateLimiter) pause(keys []limitKey, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until := l.clock().Add(d)
+	for _, k := range keys {
+		b := l.bucket(k)
+		b.pausedUntil = until
+		b.tokens = min(b.tokens, 0)
+	}
+}
+
+// refund returns a token reserved for a request that was not sent. The
+// bucket may have refilled meanwhile, so it stays capped at the burst.
+func (l *rateLimiter) refund(k limitKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(k)
+	b.tokens = min(b.tokens+1, k.limit.burst)
+}
+
+// bucket returns the refilled bucket of k, creating a full one. The caller
+// holds l.mu.
+func (l *rateLimiter) bucket(k limitKey) *tokenBucket {
+	now := l.clock()
+	b, ok := l.buckets[k.name]
+	if !ok {
+		b = &tokenBucket{tokens: k.limit.burst, last: now}
+		l.buckets[k.name] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed*k.limit.rate, k.limit.burst)
+		b.last = now
+	}
+	return b
+}
+
+// clock returns the current time.
+func (l *rateLimiter) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+// timer fires after d.
+func (l *rateLimiter) timer(d time.Duration) <-chan time.Time {
+	if l.after != nil {
+		return l.after(d)
+	}
+	return time.After(d)
+}
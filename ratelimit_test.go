@@ -0,0 +1,231 @@
+// Package main provides tests for rate limiting.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/plugin-slack/slacktest"
+)
+
+// TestMain lets the tests built on &SlackPlugin{} send as fast as they run;
+// pacing is covered by the tests below.
+func TestMain(m *testing.M) {
+	defaultRateLimiter.unlimited = true
+	os.Exit(m.Run())
+}
+
+// fakeClock drives a rate limiter without sleeping: timers fire at once and
+// advance the clock, and every wait is recorded.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+	// block makes timers never fire.
+	block bool
+}
+
+// install makes l run on the fake clock.
+func (c *fakeClock) install(l *rateLimiter) {
+	l.now = func() time.Time {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.now
+	}
+	l.after = func(d time.Duration) <-chan time.Time {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.waits = append(c.waits, d)
+		ch := make(chan time.Time, 1)
+		if !c.block {
+			c.now = c.now.Add(d)
+			ch <- c.now
+		}
+		return ch
+	}
+}
+
+// advance moves the clock forward.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// takeWaits returns and clears the recorded waits.
+func (c *fakeClock) takeWaits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	waits := c.waits
+	c.waits = nil
+	return waits
+}
+
+// TestRateLimiter tests queuing per channel and method tier, refills,
+// cancellation, refunds and pauses.
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	newLimiter := func() (*rateLimiter, *fakeClock) {
+		l := newRateLimiter()
+		clock := &fakeClock{now: time.Date(2024, 3, 5, 14, 7, 0, 0, time.UTC)}
+		clock.install(l)
+		return l, clock
+	}
+
+	t.Run("channel burst is queued", func(t *testing.T) {
+		l, clock := newLimiter()
+		post := apiLimits("chat.postMessage", SlackMessage{Channel: "#releases"})
+		for i := 0; i < 3; i++ {
+			if err := l.wait(ctx, post); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if waits := clock.takeWaits(); fmt.Sprint(waits) != "[1s 1s]" {
+			t.Errorf("expected one message per second, got waits %v", waits)
+		}
+
+		// Other channels and webhooks have their own buckets.
+		if err := l.wait(ctx, apiLimits("chat.postMessage", SlackMessage{Channel: "#ops"})); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.wait(ctx, webhookLimits(testWebhookURL)); err != nil {
+			t.Fatal(err)
+		}
+		if waits := clock.takeWaits(); len(waits) != 0 {
+			t.Errorf("expected separate buckets, got waits %v", waits)
+		}
+	})
+
+	t.Run("method tier", func(t *testing.T) {
+		l, clock := newLimiter()
+		remove := apiLimits("reactions.remove", nil)
+		for i := 0; i < tier2+1; i++ {
+			if err := l.wait(ctx, remove); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if waits := clock.takeWaits(); fmt.Sprint(waits) != "[3s]" {
+			t.Errorf("expected a burst of %d, then one call per 3s, got waits %v", tier2, waits)
+		}
+
+		clock.advance(time.Minute)
+		for i := 0; i < tier2; i++ {
+			_ = l.wait(ctx, remove)
+		}
+		if waits := clock.takeWaits(); len(waits) != 0 {
+			t.Errorf("expected the bucket to refill, got waits %v", waits)
+		}
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		l, clock := newLimiter()
+		webhook := webhookLimits(testWebhookURL)
+		_ = l.wait(ctx, webhook)
+
+		clock.block = true
+		cancelled, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- l.wait(cancelled, webhook) }()
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the queued request to be cancelled, got %v", err)
+		}
+
+		clock.block = false
+		clock.takeWaits()
+		_ = l.wait(ctx, webhook)
+		if waits := clock.takeWaits(); fmt.Sprint(waits) != "[1s]" {
+			t.Errorf("expected the cancelled request to leave the queue, got waits %v", waits)
+		}
+	})
+
+	t.Run("cancelled before taking", func(t *testing.T) {
+		l, clock := newLimiter()
+		webhook := webhookLimits(testWebhookURL)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := l.wait(cancelled, webhook); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected a cancelled context to fail, got %v", err)
+		}
+
+		_ = l.wait(ctx, webhook)
+		_ = l.wait(ctx, webhook)
+		if waits := clock.takeWaits(); fmt.Sprint(waits) != "[1s]" {
+			t.Errorf("expected the cancelled request to take no token, got waits %v", waits)
+		}
+	})
+
+	t.Run("refund is capped at the burst", func(t *testing.T) {
+		l, clock := newLimiter()
+		webhook := webhookLimits(testWebhookURL)
+		_ = l.wait(ctx, webhook)
+
+		// The bucket refills while the queued request waits, then it is cancelled.
+		cancelled, cancel := context.WithCancel(ctx)
+		l.after = func(time.Duration) <-chan time.Time {
+			clock.advance(5 * time.Second)
+			cancel()
+			return make(chan time.Time)
+		}
+		if err := l.wait(cancelled, webhook); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the queued request to be cancelled, got %v", err)
+		}
+
+		clock.install(l)
+		_ = l.wait(ctx, webhook)
+		_ = l.wait(ctx, webhook)
+		if waits := clock.takeWaits(); fmt.Sprint(waits) != "[1s]" {
+			t.Errorf("expected a burst of one after the refund, got waits %v", waits)
+		}
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		l, clock := newLimiter()
+		info := apiLimits("conversations.info", nil)
+		l.pause(info, 5*time.Second)
+		if err := l.wait(ctx, info); err != nil {
+			t.Fatal(err)
+		}
+		if waits := clock.takeWaits(); fmt.Sprint(waits) != "[5s]" {
+			t.Errorf("expected the Retry-After to be waited, got %v", waits)
+		}
+
+		l.unlimited = true
+		l.pause(info, 2*time.Second)
+		_ = l.wait(ctx, info)
+		_ = l.wait(ctx, info)
+		if waits := clock.takeWaits(); fmt.Sprint(waits) != "[2s]" {
+			t.Errorf("expected an unlimited limiter to honour only pauses, got %v", waits)
+		}
+	})
+}
+
+// TestRateLimitRetries tests that 429 answers are retried after Retry-After.
+func TestRateLimitRetries(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeSlack(t)
+	clock := &fakeClock{now: time.Now()}
+	clock.install(p.rateLimiter())
+
+	server.FailNext(slacktest.Webhook, slacktest.RateLimited(2*time.Second))
+	if err := p.sendMessage(ctx, testWebhookURL, SlackMessage{Text: "Test"}); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if calls := server.Calls(slacktest.Webhook); len(calls) != 2 {
+		t.Errorf("expected two attempts, got %d", len(calls))
+	}
+	if waits := clock.takeWaits(); fmt.Sprint(waits) != "[2s]" {
+		t.Errorf("expected the Retry-After to be waited, got %v", waits)
+	}
+
+	server.FailNext("chat.postMessage", slacktest.RateLimited(time.Second), slacktest.RateLimited(time.Second), slacktest.RateLimited(time.Second))
+	_, err := p.postMessage(ctx, "xoxb-test", SlackMessage{Channel: "#releases", Text: "Test"})
+	if err == nil || len(server.Calls("chat.postMessage")) != maxRateLimitAttempts {
+		t.Errorf("expected %d attempts before failing, got %d, %v", maxRateLimitAttempts, len(server.Calls("chat.postMessage")), err)
+	}
+}
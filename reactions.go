@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// reactionFor returns the state reaction configured for a hook, without colons.
+func (c *Config) reactionFor(hook plugin.Hook) string {
+	var name string
+	switch hook {
+	case plugin.HookPostPublish:
+		name = c.ReactionPending
+	case plugin.HookOnSuccess:
+		name = c.ReactionSuccess
+	case plugin.HookOnError:
+		name = c.ReactionFailure
+	}
+	return strings.Trim(name, ":")
+}
+
+// trackMessage records the first bot-mode message posted for a release and,
+// when reactions are enabled, moves the state reaction on it to match hook.
+// Slack is called on a copy of the record without holding stateMu, so other
+// state reads and writes do not wait for it. Tracking never fails a release:
+// errors are only logged.
+func (p *SlackPlugin) trackMessage(ctx context.Context, cfg *Config, hook plugin.Hook, releaseCtx plugin.ReleaseContext, posted *postedMessage) {
+	key := releaseKey(releaseCtx)
+	if cfg.BotToken == "" || key == "" {
+		return
+	}
+
+	rec, err := readMessageRecord(cfg.StateDir, key)
+	if err != nil {
+		logger(ctx).Warn("failed to read message state", "error", err)
+		return
+	}
+	isNew := rec == nil
+	if isNew {
+		if posted == nil || posted.TS == "" {
+			return
+		}
+		rec = &messageRecord{Channel: posted.Channel, TS: posted.TS}
+	}
+
+	reaction := rec.Reaction
+	if cfg.Reactions {
+		if name := cfg.reactionFor(hook); name != "" && name != rec.Reaction {
+			p.swapReaction(ctx, cfg.BotToken, rec, name)
+		}
+	}
+	if !isNew && rec.Reaction == reaction {
+		return
+	}
+
+	err = updateMessageRecord(cfg.StateDir, key, func(cur *messageRecord) *messageRecord {
+		if cur == nil {
+			return rec
+		}
+		// Keep what was recorded meanwhile; only the reaction changed here.
+		cur.Reaction = rec.Reaction
+		return cur
+	})
+	if err != nil {
+		logger(ctx).Warn("failed to update message state", "error", err)
+	}
+}
+
+// swapReaction replaces the state reaction on a message with name.
+func (p *SlackPlugin) swapReaction(ctx context.Context, token string, rec *messageRecord, name string) {
+	if rec.Reaction != "" {
+		err := p.react(ctx, token, "reactions.remove", rec, rec.Reaction)
+		if err != nil && !isSlackError(err, "no_reaction") {
+			logger(ctx).Warn("failed to remove reaction", "reaction", rec.Reaction, "error", err)
+		} else {
+			rec.Reaction = ""
+		}
+	}
+
+	err := p.react(ctx, token, "reactions.add", rec, name)
+	if err != nil && !isSlackError(err, "already_reacted") {
+		logger(ctx).Warn("failed to add reaction", "reaction", name, "error", err)
+		return
+	}
+	rec.Reaction = name
+}
+
+// react calls reactions.add or reactions.remove for a message.
+func (p *SlackPlugin) react(ctx context.Context, token, method string, rec *messageRecord, name string) error {
+	_, err := p.callAPI(ctx, token, method, map[string]string{
+		"channel":   rec.Channel,
+		"timestamp": rec.TS,
+		"name":      name,
+	}, nil)
+	return err
+}
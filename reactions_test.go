@@ -0,0 +1,169 @@
+// Package main provides tests for release state reactions.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// apiCall records a Web API call made to the fake Slack.
+type apiCall struct {
+	Method string
+	Body   map[string]any
+}
+
+// recordingSlackAPI fakes the Web API, recording calls and answering with
+// the response configured for each method (ok=true by default).
+func recordingSlackAPI(t *testing.T, responses map[string]map[string]any) (*[]apiCall, *sync.Mutex) {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		calls []apiCall
+	)
+	useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/api/")
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		calls = append(calls, apiCall{Method: method, Body: body})
+		mu.Unlock()
+
+		if resp, ok := responses[method]; ok {
+			writeJSON(w, resp)
+			return
+		}
+		writeJSON(w, map[string]any{"ok": true, "channel": "C123", "ts": "1700000000.000100"})
+	}))
+	return &calls, &mu
+}
+
+// reactionCalls summarizes reaction calls as "method:name".
+func reactionCalls(calls []apiCall) []string {
+	var out []string
+	for _, c := range calls {
+		if strings.HasPrefix(c.Method, "reactions.") {
+			out = append(out, c.Method+":"+c.Body["name"].(string))
+		}
+	}
+	return out
+}
+
+// TestReactions tests that the announcement reactions follow the release state.
+func TestReactions(t *testing.T) {
+	p := &SlackPlugin{}
+	ctx := context.Background()
+	releaseCtx := plugin.ReleaseContext{Version: "1.4.0", TagName: "v1.4.0", Branch: "main"}
+
+	run := func(t *testing.T, config map[string]any, hooks ...plugin.Hook) {
+		t.Helper()
+		for _, hook := range hooks {
+			resp, err := p.Execute(ctx, plugin.ExecuteRequest{Hook: hook, Config: config, Context: releaseCtx})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.Success {
+				t.Fatalf("%s: expected success, got failure: %s", hook, resp.Error)
+			}
+		}
+	}
+
+	baseConfig := func(t *testing.T) map[string]any {
+		return map[string]any{
+			"bot_token": "xoxb-1-2-3",
+			"channel":   "#releases",
+			"reactions": true,
+			"state_dir": t.TempDir(),
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, nil)
+		run(t, baseConfig(t), plugin.HookPostPublish, plugin.HookOnSuccess)
+
+		want := []string{"reactions.add:hourglass", "reactions.remove:hourglass", "reactions.add:white_check_mark"}
+		if got := reactionCalls(*calls); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+		for _, c := range *calls {
+			if strings.HasPrefix(c.Method, "reactions.") && (c.Body["channel"] != "C123" || c.Body["timestamp"] != "1700000000.000100") {
+				t.Errorf("expected reaction on stored message, got %v", c.Body)
+			}
+		}
+	})
+
+	t.Run("failure with custom emoji", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, nil)
+		config := baseConfig(t)
+		config["reaction_pending"] = ":eyes:"
+		config["reaction_failure"] = ":boom:"
+		run(t, config, plugin.HookPostPublish, plugin.HookOnError)
+
+		want := []string{"reactions.add:eyes", "reactions.remove:eyes", "reactions.add:boom"}
+		if got := reactionCalls(*calls); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("failed reaction does not fail the release", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, map[string]map[string]any{
+			"reactions.add": {"ok": false, "error": "missing_scope", "needed": "reactions:write"},
+		})
+		run(t, baseConfig(t), plugin.HookPostPublish, plugin.HookOnSuccess)
+
+		if got := reactionCalls(*calls); len(got) != 2 {
+			t.Errorf("expected two reaction attempts, got %v", got)
+		}
+	})
+
+	t.Run("already reacted is not an error", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, map[string]map[string]any{
+			"reactions.add": {"ok": false, "error": "already_reacted"},
+		})
+		config := baseConfig(t)
+		run(t, config, plugin.HookPostPublish, plugin.HookOnSuccess)
+
+		want := []string{"reactions.add:hourglass", "reactions.remove:hourglass", "reactions.add:white_check_mark"}
+		if got := reactionCalls(*calls); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("state is not locked while reacting", func(t *testing.T) {
+		var locked, reacted bool
+		useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/api/reactions.") {
+				reacted = true
+				if stateMu.TryLock() {
+					stateMu.Unlock()
+				} else {
+					locked = true
+				}
+			}
+			writeJSON(w, map[string]any{"ok": true, "channel": "C123", "ts": "1700000000.000100"})
+		}))
+		run(t, baseConfig(t), plugin.HookPostPublish, plugin.HookOnSuccess)
+
+		if !reacted || locked {
+			t.Errorf("expected reactions without holding the state lock, got reacted=%v locked=%v", reacted, locked)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, nil)
+		config := baseConfig(t)
+		delete(config, "reactions")
+		run(t, config, plugin.HookPostPublish, plugin.HookOnSuccess)
+
+		if got := reactionCalls(*calls); len(got) != 0 {
+			t.Errorf("expected no reactions, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// redactedPlaceholder replaces secret material in every string the plugin emits.
+const redactedPlaceholder = "[REDACTED]"
+
+var (
+	// webhookPathPattern matches the credential-bearing path of Slack webhook URLs.
+	webhookPathPattern = regexp.MustCompile(`(hooks\.slack\.com/(?:services|workflows|triggers)/)[^\s"'<>\\]+`)
+	// slackTokenPattern matches Slack API tokens (bot, user, app, refresh and legacy).
+	slackTokenPattern = regexp.MustCompile(`xox[abeoprs]-[A-Za-z0-9-]+`)
+)
+
+// redactor masks Slack credentials and configured secrets in strings.
+type redactor struct {
+	secrets []string
+}
+
+// newRedactor creates a redactor for the given secrets. Empty secrets are ignored.
+// Each secret is also matched in its quoted and URL-escaped forms, since Go errors
+// such as *url.Error quote or escape the URL they carry.
+func newRedactor(secrets ...string) *redactor {
+	seen := make(map[string]bool)
+	var variants []string
+	for _, s := range secrets {
+		if s == "" {
+			continue
+		}
+		quoted := strconv.Quote(s)
+		for _, v := range []string{s, quoted[1 : len(quoted)-1], url.QueryEscape(s), url.PathEscape(s)} {
+			if !seen[v] {
+				seen[v] = true
+				variants = append(variants, v)
+			}
+		}
+	}
+	// Replace longer secrets first so a secret containing another is fully masked.
+	sort.Slice(variants, func(i, j int) bool { return len(variants[i]) > len(variants[j]) })
+	return &redactor{secrets: variants}
+}
+
+// String returns s with all known secret material masked.
+func (r *redactor) String(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+	}
+	s = webhookPathPattern.ReplaceAllString(s, "${1}"+redactedPlaceholder)
+	s = slackTokenPattern.ReplaceAllString(s, redactedPlaceholder)
+	return s
+}
+
+// Value redacts strings nested anywhere inside an output value.
+func (r *redactor) Value(v any) any {
+	switch val := v.(type) {
+	case string:
+		return r.String(val)
+	case []string:
+		out := make([]string, len(val))
+		for i, s := range val {
+			out[i] = r.String(s)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = r.Value(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = r.Value(item)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(val))
+		for k, s := range val {
+			out[k] = r.String(s)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Response redacts the message, error and outputs of an execute response in place.
+func (r *redactor) Response(resp *plugin.ExecuteResponse) *plugin.ExecuteResponse {
+	if resp == nil {
+		return nil
+	}
+	resp.Message = r.String(resp.Message)
+	resp.Error = r.String(resp.Error)
+	if resp.Outputs != nil {
+		resp.Outputs = r.Value(resp.Outputs).(map[string]any)
+	}
+	return resp
+}
+
+// Validation redacts the messages of a validation response in place.
+func (r *redactor) Validation(resp *plugin.ValidateResponse) *plugin.ValidateResponse {
+	if resp == nil {
+		return nil
+	}
+	for i := range resp.Errors {
+		resp.Errors[i].Message = r.String(resp.Errors[i].Message)
+	}
+	return resp
+}
+
+// secrets returns the configured values that must never appear in plugin output.
+func (c *Config) secrets() []string {
+	secrets := []string{c.WebhookURL, c.BotToken, c.ApprovalSigningSecret, c.AuditHMACKey}
+	for _, d := range c.Destinations {
+		secrets = append(secrets, d.Webhook)
+	}
+	return secrets
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Quiet hours modes.
+const (
+	// quietModeSilent sends immediately, without mentions.
+	quietModeSilent = "silent"
+	// quietModeSchedule schedules the message for the end of the window.
+	quietModeSchedule = "schedule"
+)
+
+// maxScheduleAhead is how far ahead chat.scheduleMessage accepts messages.
+const maxScheduleAhead = 120 * 24 * time.Hour
+
+// QuietHours is a daily window in which nobody should be pinged.
+type QuietHours struct {
+	// Timezone is the IANA time zone of the window, e.g. "Europe/Berlin".
+	Timezone string `json:"timezone"`
+	// Start is the start of the window as HH:MM.
+	Start string `json:"start"`
+	// End is the end of the window as HH:MM; it may be earlier than Start.
+	End string `json:"end"`
+	// Mode is "silent" or "schedule".
+	Mode string `json:"mode"`
+}
+
+// deliveryPlan describes when and how a message is delivered.
+type deliveryPlan struct {
+	// Now is when the plan was made; messages are timestamped with it.
+	Now time.Time
+	// At is when the message is delivered.
+	At time.Time
+	// Scheduled is set when the message is deferred with chat.scheduleMessage.
+	Scheduled bool
+	// Quiet is set when the message is sent during quiet hours, without mentions.
+	Quiet bool
+}
+
+// scheduleMessageResponse is the response of chat.scheduleMessage.
+type scheduleMessageResponse struct {
+	apiResponse
+	Channel            string `json:"channel"`
+	ScheduledMessageID string `json:"scheduled_message_id"`
+}
+
+// scheduledMessage is the body of chat.scheduleMessage.
+type scheduledMessage struct {
+	SlackMessage
+	PostAt int64 `json:"post_at"`
+}
+
+// parseQuietHours parses the quiet_hours object, or returns nil when unset.
+func parseQuietHours(raw map[string]any) *QuietHours {
+	if raw == nil {
+		return nil
+	}
+	parser := helpers.NewConfigParser(raw)
+	return &QuietHours{
+		Timezone: parser.GetString("timezone", "", "UTC"),
+		Start:    parser.GetString("start", "", ""),
+		End:      parser.GetString("end", "", ""),
+		Mode:     parser.GetString("mode", "", quietModeSilent),
+	}
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// window returns the window bounds in minutes and its location.
+func (q *QuietHours) window() (start, end int, loc *time.Location, err error) {
+	if loc, err = time.LoadLocation(q.Timezone); err != nil {
+		return 0, 0, nil, fmt.Errorf("unknown timezone %q", q.Timezone)
+	}
+	if start, err = parseClock(q.Start); err != nil {
+		return 0, 0, nil, err
+	}
+	if end, err = parseClock(q.End); err != nil {
+		return 0, 0, nil, err
+	}
+	return start, end, loc, nil
+}
+
+// quietUntil reports whether t falls within the window and, if so, when the
+// window ends. Windows may span midnight; an empty window is never active.
+func (q *QuietHours) quietUntil(t time.Time) (time.Time, bool, error) {
+	start, end, loc, err := q.window()
+	if err != nil || start == end {
+		return time.Time{}, false, err
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	inside := start <= minute && minute < end
+	if start > end {
+		inside = minute >= start || minute < end
+	}
+	if !inside {
+		return time.Time{}, false, nil
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = time.Date(local.Year(), local.Month(), local.Day()+1, end/60, end%60, 0, 0, loc)
+	}
+	return until, true, nil
+}
+
+// parseScheduleTime parses a rendered schedule_at value: RFC 3339 or Unix seconds.
+func parseScheduleTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("schedule_at %q is not an RFC 3339 time or Unix timestamp", s)
+}
+
+// planDelivery decides when a message is delivered and whether it may ping.
+// Scheduling needs a bot token; in webhook mode messages are always sent now,
+// and quiet hours only drop the mentions.
+func planDelivery(cfg *Config, releaseCtx plugin.ReleaseContext, now time.Time) (deliveryPlan, error) {
+	plan := deliveryPlan{Now: now, At: now}
+	canSchedule := cfg.BotToken != ""
+
+	if cfg.ScheduleAt != "" && canSchedule {
+		rendered, err := cfg.renderTemplate("schedule_at", cfg.ScheduleAt, releaseCtx)
+		if err != nil {
+			return plan, err
+		}
+		if rendered != "" {
+			at, err := parseScheduleTime(rendered)
+			if err != nil {
+				return plan, err
+			}
+			if at.After(now) {
+				plan.At, plan.Scheduled = at, true
+			}
+		}
+	}
+
+	if cfg.QuietHours != nil {
+		until, quiet, err := cfg.QuietHours.quietUntil(plan.At)
+		if err != nil {
+			return plan, fmt.Errorf("invalid quiet_hours: %w", err)
+		}
+		if quiet {
+			if cfg.QuietHours.Mode == quietModeSchedule && canSchedule {
+				plan.At, plan.Scheduled = until, true
+			} else {
+				plan.Quiet = true
+			}
+		}
+	}
+
+	if plan.Scheduled && plan.At.Sub(now) > maxScheduleAhead {
+		return plan, fmt.Errorf("cannot schedule a message more than %d days ahead", int(maxScheduleAhead.Hours()/24))
+	}
+	return plan, nil
+}
+
+// mentions returns the mention text for a message delivered with this plan.
+func (d deliveryPlan) mentions(mentions []string) string {
+	if d.Quiet {
+		return ""
+	}
+	return buildSlackMentions(mentions)
+}
+
+// outputs returns the dry-run outputs describing the plan.
+func (d deliveryPlan) outputs() map[string]any {
+	mode := "immediate"
+	switch {
+	case d.Scheduled:
+		mode = "scheduled"
+	case d.Quiet:
+		mode = "quiet"
+	}
+	return map[string]any{
+		"delivery_at": d.At.UTC().Format(time.RFC3339),
+		"delivery":    mode,
+	}
+}
+
+// sentMessage describes a delivered notification of the given kind.
+func (d deliveryPlan) sentMessage(kind string) string {
+	if d.Scheduled {
+		return fmt.Sprintf("Scheduled Slack %s notification for %s", kind, d.At.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("Sent Slack %s notification", kind)
+}
+
+// dispatch sends msg now or schedules it, following plan.
+func (p *SlackPlugin) dispatch(ctx context.Context, cfg *Config, msg SlackMessage, plan deliveryPlan) (*postedMessage, error) {
+	// Webhook destinations cannot schedule and are delivered immediately.
+	if !plan.Scheduled || cfg.BotToken == "" {
+		return p.deliver(ctx, cfg, msg)
+	}
+
+	var out scheduleMessageResponse
+	if _, err := p.callAPI(ctx, cfg.BotToken, "chat.scheduleMessage", scheduledMessage{
+		SlackMessage: msg,
+		PostAt:       plan.At.Unix(),
+	}, &out); err != nil {
+		return nil, err
+	}
+	// Scheduled messages have no ts until they are posted.
+	return &postedMessage{Channel: out.Channel}, nil
+}
+
+// validateQuietHours checks the quiet_hours window and schedule_at settings.
+func validateQuietHours(vb, warnings *helpers.ValidationBuilder, config map[string]any) {
+	parser := helpers.NewConfigParser(config)
+	botToken := parser.GetString("bot_token", "SLACK_BOT_TOKEN", "")
+
+	if raw, ok := config["quiet_hours"].(map[string]any); ok {
+		q := parseQuietHours(raw)
+		if q.Start == "" || q.End == "" {
+			vb.AddErrorWithCode("quiet_hours", "quiet_hours requires start and end", "required")
+		} else if _, _, _, err := q.window(); err != nil {
+			vb.AddErrorWithCode("quiet_hours", err.Error(), "format")
+		}
+		if q.Mode == quietModeSchedule && botToken == "" {
+			warnings.AddErrorWithCode("quiet_hours.mode",
+				"scheduling requires bot_token; with a webhook, messages in quiet hours are sent without mentions",
+				warningCodePrefix+"requires_bot_token")
+		}
+	}
+
+	if s, ok := config["schedule_at"].(string); ok && strings.TrimSpace(s) != "" && botToken == "" {
+		warnings.AddErrorWithCode("schedule_at", "schedule_at requires bot_token and is ignored in webhook mode", warningCodePrefix+"requires_bot_token")
+	}
+}
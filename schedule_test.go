@@ -0,0 +1,201 @@
+// Package main provides tests for scheduled and quiet-hours delivery.
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestQuietUntil tests window membership and end computation.
+func TestQuietUntil(t *testing.T) {
+	overnight := &QuietHours{Timezone: "Europe/Berlin", Start: "22:00", End: "07:30"}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		q         *QuietHours
+		at        time.Time
+		wantQuiet bool
+		wantUntil time.Time
+	}{
+		{
+			name:      "before midnight",
+			q:         overnight,
+			at:        time.Date(2024, 3, 4, 23, 15, 0, 0, berlin),
+			wantQuiet: true,
+			wantUntil: time.Date(2024, 3, 5, 7, 30, 0, 0, berlin),
+		},
+		{
+			name:      "after midnight",
+			q:         overnight,
+			at:        time.Date(2024, 3, 5, 3, 0, 0, 0, berlin),
+			wantQuiet: true,
+			wantUntil: time.Date(2024, 3, 5, 7, 30, 0, 0, berlin),
+		},
+		{
+			name: "outside window",
+			q:    overnight,
+			at:   time.Date(2024, 3, 5, 7, 30, 0, 0, berlin),
+		},
+		{
+			name:      "same-day window in UTC input",
+			q:         &QuietHours{Timezone: "UTC", Start: "12:00", End: "13:00"},
+			at:        time.Date(2024, 3, 5, 12, 59, 0, 0, time.UTC),
+			wantQuiet: true,
+			wantUntil: time.Date(2024, 3, 5, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "empty window",
+			q:    &QuietHours{Timezone: "UTC", Start: "12:00", End: "12:00"},
+			at:   time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet, err := tt.q.quietUntil(tt.at)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if quiet != tt.wantQuiet {
+				t.Fatalf("expected quiet=%v, got %v", tt.wantQuiet, quiet)
+			}
+			if quiet && !until.Equal(tt.wantUntil) {
+				t.Errorf("expected quiet until %v, got %v", tt.wantUntil, until)
+			}
+		})
+	}
+
+	if _, _, err := (&QuietHours{Timezone: "Mars/Olympus", Start: "22:00", End: "07:00"}).quietUntil(time.Now()); err == nil {
+		t.Error("expected error for unknown timezone")
+	}
+}
+
+// TestPlanDelivery tests how schedule_at and quiet hours combine.
+func TestPlanDelivery(t *testing.T) {
+	now := time.Date(2024, 3, 5, 2, 0, 0, 0, time.UTC)
+	night := &QuietHours{Timezone: "UTC", Start: "22:00", End: "07:00", Mode: quietModeSilent}
+	nightSchedule := &QuietHours{Timezone: "UTC", Start: "22:00", End: "07:00", Mode: quietModeSchedule}
+	morning := time.Date(2024, 3, 5, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		cfg           *Config
+		wantAt        time.Time
+		wantScheduled bool
+		wantQuiet     bool
+		wantErr       string
+	}{
+		{name: "no settings", cfg: &Config{}, wantAt: now},
+		{name: "silent quiet hours", cfg: &Config{QuietHours: night}, wantAt: now, wantQuiet: true},
+		{name: "scheduled quiet hours", cfg: &Config{BotToken: "xoxb-1", QuietHours: nightSchedule}, wantAt: morning, wantScheduled: true},
+		{name: "schedule falls back to silent with webhook", cfg: &Config{QuietHours: nightSchedule}, wantAt: now, wantQuiet: true},
+		{name: "schedule_at in the future", cfg: &Config{BotToken: "xoxb-1", ScheduleAt: "2024-03-05T09:00:00Z"},
+			wantAt: time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC), wantScheduled: true},
+		{name: "schedule_at in the past", cfg: &Config{BotToken: "xoxb-1", ScheduleAt: "1709600000"}, wantAt: now},
+		{name: "schedule_at inside quiet hours", cfg: &Config{BotToken: "xoxb-1", ScheduleAt: "2024-03-05T05:00:00Z", QuietHours: nightSchedule},
+			wantAt: morning, wantScheduled: true},
+		{name: "schedule_at ignored with webhook", cfg: &Config{ScheduleAt: "2024-03-05T09:00:00Z"}, wantAt: now},
+		{name: "schedule_at from environment", cfg: &Config{BotToken: "xoxb-1", ScheduleAt: "{{.Environment.DEPLOY_AT}}"}, wantAt: now},
+		{name: "unparsable schedule_at", cfg: &Config{BotToken: "xoxb-1", ScheduleAt: "tomorrow"}, wantErr: "not an RFC 3339"},
+		{name: "too far ahead", cfg: &Config{BotToken: "xoxb-1", ScheduleAt: "2025-03-05T09:00:00Z"}, wantErr: "more than 120 days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planDelivery(tt.cfg, plugin.ReleaseContext{Version: "1.0.0"}, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !plan.At.Equal(tt.wantAt) || plan.Scheduled != tt.wantScheduled || plan.Quiet != tt.wantQuiet {
+				t.Errorf("unexpected plan %+v", plan)
+			}
+		})
+	}
+}
+
+// TestQuietHoursDelivery tests quiet-hours delivery end to end.
+func TestQuietHoursDelivery(t *testing.T) {
+	// A window around the current time, so the test does not depend on the clock.
+	now := time.Now().UTC()
+	window := map[string]any{
+		"timezone": "UTC",
+		"start":    now.Add(-time.Hour).Format("15:04"),
+		"end":      now.Add(time.Hour).Format("15:04"),
+	}
+	releaseCtx := plugin.ReleaseContext{Version: "1.4.0", TagName: "v1.4.0", Branch: "main"}
+
+	t.Run("silent drops mentions", func(t *testing.T) {
+		resp, _ := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPostPlan,
+			Config: map[string]any{
+				"webhook":        "https://hooks.slack.com/services/T/B/x",
+				"notify_on_plan": true,
+				"mentions":       []any{"@here"},
+				"quiet_hours":    window,
+			},
+			Context: releaseCtx,
+			DryRun:  true,
+		})
+		if resp.Outputs["delivery"] != "quiet" {
+			t.Errorf("expected quiet delivery, got %v", resp.Outputs)
+		}
+		if text, _ := resp.Outputs["text"].(string); strings.Contains(text, "<!here>") {
+			t.Errorf("expected no mentions in quiet hours, got %q", text)
+		}
+	})
+
+	t.Run("schedule posts at the end of the window", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, nil)
+		scheduled := map[string]any{}
+		for k, v := range window {
+			scheduled[k] = v
+		}
+		scheduled["mode"] = "schedule"
+
+		resp, _ := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookOnSuccess,
+			Config: map[string]any{
+				"bot_token": "xoxb-1-2-3", "channel": "#releases", "state_dir": t.TempDir(),
+				"mentions": []any{"@here"}, "quiet_hours": scheduled,
+			},
+			Context: releaseCtx,
+		})
+		if !resp.Success || !strings.HasPrefix(resp.Message, "Scheduled Slack success notification") {
+			t.Fatalf("expected scheduled notification, got %+v", resp)
+		}
+
+		var found bool
+		for _, c := range *calls {
+			if c.Method == "chat.postMessage" {
+				t.Error("expected no immediate post")
+			}
+			if c.Method == "chat.scheduleMessage" {
+				found = true
+				postAt, _ := c.Body["post_at"].(float64)
+				if d := time.Unix(int64(postAt), 0).Sub(now); d <= 0 || d > time.Hour+time.Minute {
+					t.Errorf("expected post_at at the end of the window, got %v", d)
+				}
+				if text, _ := c.Body["text"].(string); text != "<!here>" {
+					t.Errorf("expected mentions in scheduled message, got %q", text)
+				}
+			}
+		}
+		if !found {
+			t.Error("expected chat.scheduleMessage call")
+		}
+	})
+}
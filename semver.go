@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Release stabilities, as classified from the version.
+const (
+	// stabilityStable is a plain release such as "1.2.0".
+	stabilityStable = "stable"
+	// stabilityPrerelease is a release candidate, beta or alpha such as "1.2.0-rc.1".
+	stabilityPrerelease = "prerelease"
+	// stabilityBuild is a CI build such as "1.2.0-nightly.20240301" or "1.2.0+sha.abc".
+	stabilityBuild = "build"
+)
+
+// semverPattern matches a semantic version with an optional "v" prefix.
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
+// channelPattern extracts the channel name from a prerelease identifier,
+// e.g. "rc" from "rc1".
+var channelPattern = regexp.MustCompile(`^[A-Za-z-]+`)
+
+// buildChannels are prerelease channels produced by CI rather than cut by hand.
+var buildChannels = map[string]bool{
+	"nightly":  true,
+	"dev":      true,
+	"snapshot": true,
+	"canary":   true,
+}
+
+// semver is a parsed semantic version.
+type semver struct {
+	Major, Minor, Patch string
+	// Prerelease is the dot-separated prerelease suffix, e.g. "rc.1".
+	Prerelease string
+	// Build is the build metadata, e.g. "sha.abc".
+	Build string
+}
+
+// parseSemver parses a version. It reports false for versions that are not
+// semantic versions.
+func parseSemver(version string) (semver, bool) {
+	m := semverPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if m == nil {
+		return semver{}, false
+	}
+	return semver{Major: m[1], Minor: m[2], Patch: m[3], Prerelease: m[4], Build: m[5]}, true
+}
+
+// core returns the version without prerelease and build suffixes.
+func (v semver) core() string {
+	return fmt.Sprintf("%s.%s.%s", v.Major, v.Minor, v.Patch)
+}
+
+// channel returns the lower-cased prerelease channel, such as "rc", "beta"
+// or "nightly", or "" for stable versions and numeric prereleases.
+func (v semver) channel() string {
+	first, _, _ := strings.Cut(v.Prerelease, ".")
+	return strings.ToLower(strings.TrimRight(channelPattern.FindString(first), "-"))
+}
+
+// stability classifies the version.
+func (v semver) stability() string {
+	switch {
+	case v.Build != "" || buildChannels[v.channel()]:
+		return stabilityBuild
+	case v.Prerelease != "":
+		return stabilityPrerelease
+	default:
+		return stabilityStable
+	}
+}
+
+// releaseStability classifies a release. Versions that are not semantic
+// versions are treated as stable.
+func releaseStability(releaseCtx plugin.ReleaseContext) string {
+	v, ok := parseSemver(releaseCtx.Version)
+	if !ok {
+		return stabilityStable
+	}
+	return v.stability()
+}
+
+// prereleaseChannel returns the prerelease channel of a release, or "".
+func prereleaseChannel(releaseCtx plugin.ReleaseContext) string {
+	v, _ := parseSemver(releaseCtx.Version)
+	return v.channel()
+}
+
+// promotedFrom returns the previous version when a stable release follows
+// one of its own prereleases, e.g. "2.0.0-rc.3" for "2.0.0", and "" otherwise.
+func promotedFrom(releaseCtx plugin.ReleaseContext) string {
+	v, ok := parseSemver(releaseCtx.Version)
+	if !ok || v.stability() != stabilityStable {
+		return ""
+	}
+	prev, ok := parseSemver(releaseCtx.PreviousVersion)
+	if !ok || prev.stability() != stabilityPrerelease || prev.core() != v.core() {
+		return ""
+	}
+	return releaseCtx.PreviousVersion
+}
+
+// notifiesRelease reports whether success and lifecycle notifications are
+// sent for a release. Error notifications are always sent.
+func (c *Config) notifiesRelease(releaseCtx plugin.ReleaseContext) bool {
+	return c.NotifyOnPrerelease || releaseStability(releaseCtx) == stabilityStable
+}
@@ -0,0 +1,141 @@
+// Package main provides tests for semantic version classification.
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestReleaseStability tests classifying versions as stable, prerelease or build.
+func TestReleaseStability(t *testing.T) {
+	tests := []struct {
+		version       string
+		wantStability string
+		wantChannel   string
+	}{
+		{"1.2.0", stabilityStable, ""},
+		{"v1.2.0", stabilityStable, ""},
+		{"1.2.0-rc.1", stabilityPrerelease, "rc"},
+		{"1.2.0-beta2", stabilityPrerelease, "beta"},
+		{"1.2.0-Alpha.3", stabilityPrerelease, "alpha"},
+		{"1.2.0-0.3.7", stabilityPrerelease, ""},
+		{"1.2.0-nightly.20240301", stabilityBuild, "nightly"},
+		{"1.2.0-dev", stabilityBuild, "dev"},
+		{"1.2.0+sha.abc123", stabilityBuild, ""},
+		{"1.2.0-rc.1+sha.abc123", stabilityBuild, "rc"},
+		{"1.2", stabilityStable, ""},
+		{"01.2.0-rc.1", stabilityStable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			releaseCtx := plugin.ReleaseContext{Version: tt.version}
+			if got := releaseStability(releaseCtx); got != tt.wantStability {
+				t.Errorf("expected stability %q, got %q", tt.wantStability, got)
+			}
+			if got := prereleaseChannel(releaseCtx); got != tt.wantChannel {
+				t.Errorf("expected channel %q, got %q", tt.wantChannel, got)
+			}
+		})
+	}
+}
+
+// TestPromotedFrom tests detecting a stable release that follows its own prerelease.
+func TestPromotedFrom(t *testing.T) {
+	tests := []struct {
+		version, previous, want string
+	}{
+		{"2.0.0", "2.0.0-rc.3", "2.0.0-rc.3"},
+		{"v2.0.0", "v2.0.0-beta.1", "v2.0.0-beta.1"},
+		{"2.0.0", "1.9.0", ""},
+		{"2.0.1", "2.0.0-rc.3", ""},
+		{"2.0.0", "2.0.0-nightly.1", ""},
+		{"2.0.0-rc.4", "2.0.0-rc.3", ""},
+		{"2.0.0", "", ""},
+	}
+
+	for _, tt := range tests {
+		got := promotedFrom(plugin.ReleaseContext{Version: tt.version, PreviousVersion: tt.previous})
+		if got != tt.want {
+			t.Errorf("promotedFrom(%q, %q) = %q, want %q", tt.version, tt.previous, got, tt.want)
+		}
+	}
+}
+
+// TestPrereleaseNotifications tests routing, suppressing and templating
+// prerelease notifications.
+func TestPrereleaseNotifications(t *testing.T) {
+	execute := func(t *testing.T, hook plugin.Hook, version string, extra map[string]any) (*plugin.ExecuteResponse, []apiCall) {
+		t.Helper()
+		calls, _ := recordingSlackAPI(t, nil)
+		config := map[string]any{
+			"bot_token":          "xoxb-1-2-3",
+			"channel":            "#releases",
+			"prerelease_channel": "#releases-rc",
+			"state_dir":          t.TempDir(),
+		}
+		for k, v := range extra {
+			config[k] = v
+		}
+		resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    hook,
+			Config:  config,
+			Context: plugin.ReleaseContext{Version: version, PreviousVersion: "2.0.0-rc.2", ReleaseType: "major"},
+		})
+		if err != nil || !resp.Success {
+			t.Fatalf("expected success, got %v / %+v", err, resp)
+		}
+		return resp, *calls
+	}
+	posts := func(calls []apiCall) []apiCall {
+		var out []apiCall
+		for _, c := range calls {
+			if c.Method == "chat.postMessage" {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	t.Run("prereleases go to the prerelease channel", func(t *testing.T) {
+		_, calls := execute(t, plugin.HookOnSuccess, "2.0.0-rc.3", map[string]any{
+			"prerelease_template": "{{.PrereleaseChannel}} {{.Version}} is ready for testing",
+		})
+		p := posts(calls)
+		if len(p) != 1 || p[0].Body["channel"] != "#releases-rc" || p[0].Body["text"] != "rc 2.0.0-rc.3 is ready for testing" {
+			t.Errorf("unexpected posts %+v", p)
+		}
+	})
+
+	t.Run("stable release is annotated", func(t *testing.T) {
+		_, calls := execute(t, plugin.HookOnSuccess, "2.0.0", nil)
+		p := posts(calls)
+		if len(p) != 1 || p[0].Body["channel"] != "#releases" {
+			t.Fatalf("unexpected posts %+v", p)
+		}
+		att := p[0].Body["attachments"].([]any)[0].(map[string]any)
+		var found bool
+		for _, f := range att["fields"].([]any) {
+			field := f.(map[string]any)
+			found = found || (field["title"] == "Promoted From" && field["value"] == "2.0.0-rc.2")
+		}
+		if !found {
+			t.Errorf("expected promoted from field, got %v", att["fields"])
+		}
+	})
+
+	t.Run("disabled prerelease notifications", func(t *testing.T) {
+		resp, calls := execute(t, plugin.HookOnSuccess, "2.1.0-nightly.20240301", map[string]any{"notify_on_prerelease": false})
+		if len(posts(calls)) != 0 || !strings.Contains(resp.Message, "disabled") {
+			t.Errorf("expected no post, got %+v / %+v", resp, calls)
+		}
+
+		_, calls = execute(t, plugin.HookOnError, "2.1.0-nightly.20240301", map[string]any{"notify_on_prerelease": false})
+		if p := posts(calls); len(p) != 1 || p[0].Body["channel"] != "#releases-rc" {
+			t.Errorf("expected the error notification in the prerelease channel, got %+v", p)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// slackAPIBaseURL is the base URL of the Slack Web API.
+var slackAPIBaseURL = "https://slack.com/api/"
+
+// maxAPIResponseSize caps how much of a Web API response is read.
+const maxAPIResponseSize = 1 << 20
+
+// apiResponse is the envelope shared by all Slack Web API responses.
+type apiResponse struct {
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+	Needed   string `json:"needed,omitempty"`
+	Provided string `json:"provided,omitempty"`
+}
+
+// slackAPIError is returned when a Web API call answers with ok=false.
+type slackAPIError struct {
+	Method string
+	Code   string
+	Needed string
+}
+
+func (e *slackAPIError) Error() string {
+	if e.Needed != "" {
+		return fmt.Sprintf("slack %s failed: %s (needed scope: %s)", e.Method, e.Code, e.Needed)
+	}
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+// isSlackError reports whether err is a Web API error with the given code.
+func isSlackError(err error, code string) bool {
+	var apiErr *slackAPIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// postedMessage identifies a message after it has been sent.
+// Webhook deliveries do not return identifiers, so all fields are empty.
+type postedMessage struct {
+	Channel   string
+	TS        string
+	Permalink string
+}
+
+// getPermalinkResponse is the response of chat.getPermalink.
+type getPermalinkResponse struct {
+	apiResponse
+	Permalink string `json:"permalink"`
+}
+
+// chatPostMessageResponse is the response of chat.postMessage.
+type chatPostMessageResponse struct {
+	apiResponse
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+}
+
+// httpClient returns the client for requests to Slack.
+func (p *SlackPlugin) httpClient() *http.Client {
+	if p.client != nil {
+		return p.client
+	}
+	return defaultHTTPClient
+}
+
+// apiURL returns the URL of a Web API method.
+func (p *SlackPlugin) apiURL(method string) string {
+	if p.baseURL != "" {
+		return strings.TrimSuffix(p.baseURL, "/") + "/api/" + method
+	}
+	return slackAPIBaseURL + method
+}
+
+// slackURL moves a URL on a Slack host, such as a webhook or response URL,
+// to the base URL when one is set. Other URLs are returned unchanged.
+func (p *SlackPlugin) slackURL(raw string) string {
+	if p.baseURL == "" {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || !allowedSlackHosts[u.Host] {
+		return raw
+	}
+	base, err := url.Parse(p.baseURL)
+	if err != nil {
+		return raw
+	}
+	u.Scheme, u.Host = base.Scheme, base.Host
+	return u.String()
+}
+
+// callAPI invokes a Slack Web API method and decodes the response into out.
+// A url.Values body is sent form-encoded (required by read methods such as
+// conversations.info); any other body is sent as JSON.
+// The call waits for the rate limits of its channel and method tier, and is
+// retried when Slack answers 429 with a short Retry-After.
+// The response headers are returned so callers can inspect X-OAuth-Scopes.
+func (p *SlackPlugin) callAPI(ctx context.Context, token, method string, body any, out any) (http.Header, error) {
+	var (
+		payload     []byte
+		contentType = "application/x-www-form-urlencoded"
+	)
+	switch b := body.(type) {
+	case url.Values:
+		payload = []byte(b.Encode())
+	case nil:
+	default:
+		var err error
+		if payload, err = json.Marshal(b); err != nil {
+			return nil, fmt.Errorf("failed to marshal %s request: %w", method, err)
+		}
+		contentType = "application/json; charset=utf-8"
+	}
+
+	limits := apiLimits(method, body)
+	for attempt := 1; ; attempt++ {
+		if err := p.rateLimiter().wait(ctx, limits); err != nil {
+			return nil, err
+		}
+		header, status, err := p.sendAPIRequest(ctx, token, method, contentType, payload, out, attempt)
+		d, retry := retryAfter(status, header)
+		if !retry || attempt == maxRateLimitAttempts {
+			return header, err
+		}
+		p.rateLimiter().pause(limits, d)
+	}
+}
+
+// sendAPIRequest sends one Web API request and returns the response
+// headers and status.
+func (p *SlackPlugin) sendAPIRequest(ctx context.Context, token, method, contentType string, payload []byte, out any, attempt int) (header http.Header, status int, err error) {
+	code := ""
+	start := time.Now()
+	defer func() { logRequest(ctx, method, attempt, len(payload), start, status, code, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.apiURL(method), bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	status = resp.StatusCode
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.Header, status, fmt.Errorf("slack %s returned status %d", method, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAPIResponseSize))
+	if err != nil {
+		return resp.Header, status, fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+
+	var envelope apiResponse
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return resp.Header, status, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if !envelope.OK {
+		code = envelope.Error
+		return resp.Header, status, &slackAPIError{Method: method, Code: envelope.Error, Needed: envelope.Needed}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.Header, status, fmt.Errorf("failed to decode %s response: %w", method, err)
+		}
+	}
+	return resp.Header, status, nil
+}
+
+// postMessage sends a message through chat.postMessage.
+func (p *SlackPlugin) postMessage(ctx context.Context, token string, msg SlackMessage) (*postedMessage, error) {
+	var out chatPostMessageResponse
+	if _, err := p.callAPI(ctx, token, "chat.postMessage", msg, &out); err != nil {
+		return nil, err
+	}
+	return &postedMessage{Channel: out.Channel, TS: out.TS}, nil
+}
+
+// permalink returns the link to a posted message.
+func (p *SlackPlugin) permalink(ctx context.Context, token string, posted *postedMessage) (string, error) {
+	var out getPermalinkResponse
+	if _, err := p.callAPI(ctx, token, "chat.getPermalink", url.Values{
+		"channel":    {posted.Channel},
+		"message_ts": {posted.TS},
+	}, &out); err != nil {
+		return "", err
+	}
+	return out.Permalink, nil
+}
+
+// deliver sends a message using the bot token when configured, or the webhook otherwise.
+func (p *SlackPlugin) deliver(ctx context.Context, cfg *Config, msg SlackMessage) (*postedMessage, error) {
+	if cfg.BotToken != "" {
+		return p.postMessage(ctx, cfg.BotToken, msg)
+	}
+	if err := p.sendMessage(ctx, cfg.WebhookURL, msg); err != nil {
+		return nil, err
+	}
+	return &postedMessage{}, nil
+}
+
+// hasScope reports whether a comma-separated X-OAuth-Scopes header grants scope.
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Split(scopes, ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,480 @@
+// Package slacktest provides a fake Slack server for tests and local
+// development. It accepts incoming webhooks and implements the Web API methods
+// the plugin uses, records every request and can be scripted to fail.
+//
+// Point the plugin at Server.URL as its base URL: Web API calls then go to
+// URL/api/<method>, and webhook URLs on hooks.slack.com are sent to the same
+// path on the fake, so configurations keep their real webhook URLs.
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook is the method name under which webhook requests are recorded and
+// failures are scripted.
+const Webhook = "webhook"
+
+// Request is a request received by the fake.
+type Request struct {
+	// Method is the Web API method, Webhook, or "upload" for file content.
+	Method string
+	// Path is the request path.
+	Path string
+	// Header holds the request headers.
+	Header http.Header
+	// Body is the decoded JSON or form body; form values are strings.
+	Body map[string]any
+	// Raw is the undecoded body.
+	Raw []byte
+}
+
+// Failure is a scripted response that replaces the fake's normal answer.
+type Failure struct {
+	// Status is the HTTP status; zero means 200 for Web API errors and 400
+	// for webhook errors.
+	Status int
+	// RetryAfter is sent as the Retry-After header when positive.
+	RetryAfter time.Duration
+	// Error is the Slack error code returned in the body.
+	Error string
+}
+
+// RateLimited answers 429 with a Retry-After header.
+func RateLimited(retryAfter time.Duration) Failure {
+	return Failure{Status: http.StatusTooManyRequests, RetryAfter: retryAfter, Error: "ratelimited"}
+}
+
+// ServerError answers 500.
+func ServerError() Failure {
+	return Failure{Status: http.StatusInternalServerError, Error: "internal_error"}
+}
+
+// InvalidPayload rejects the payload as Slack does for malformed messages.
+func InvalidPayload() Failure {
+	return Failure{Error: "invalid_payload"}
+}
+
+// APIError answers a Web API call with ok=false and the given error code.
+func APIError(code string) Failure {
+	return Failure{Error: code}
+}
+
+// reaction identifies a reaction on a message.
+type reaction struct {
+	channel, ts, name string
+}
+
+// Server is a fake Slack server. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL of the fake, e.g. http://127.0.0.1:1234.
+	URL string
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	requests  []Request
+	failures  map[string][]Failure
+	responses map[string]map[string]any
+	scopes    string
+	users     map[string]string
+	channels  map[string]string
+	messages  map[string]bool
+	reactions map[reaction]bool
+	files     map[string][]byte
+	lastTS    int64
+	lastID    int
+}
+
+// NewServer starts a fake Slack server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		failures:  map[string][]Failure{},
+		responses: map[string]map[string]any{},
+		scopes:    "chat:write,chat:write.public,reactions:write,files:write,channels:read,channels:manage,bookmarks:write,users:read.email",
+		users:     map[string]string{},
+		channels:  map[string]string{},
+		messages:  map[string]bool{},
+		reactions: map[reaction]bool{},
+		files:     map[string][]byte{},
+		lastTS:    1700000000,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns an HTTP client for the server.
+func (s *Server) Client() *http.Client {
+	return s.server.Client()
+}
+
+// WebhookURL returns a webhook URL served directly by the fake.
+func (s *Server) WebhookURL() string {
+	return s.URL + "/services/T00000000/B00000000/slacktest"
+}
+
+// FailNext scripts the next requests for method to fail, in order.
+// Use Webhook as the method for incoming webhooks.
+func (s *Server) FailNext(method string, failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method] = append(s.failures[method], failures...)
+}
+
+// SetResponse replaces the answer to a Web API method.
+func (s *Server) SetResponse(method string, body map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[method] = body
+}
+
+// SetScopes sets the X-OAuth-Scopes header returned by auth.test.
+func (s *Server) SetScopes(scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scopes = strings.Join(scopes, ",")
+}
+
+// AddUser makes users.lookupByEmail find a user.
+func (s *Server) AddUser(email, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[strings.ToLower(email)] = id
+}
+
+// Requests returns all requests received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Calls returns the requests received for method.
+func (s *Server) Calls(method string) []Request {
+	var out []Request
+	for _, r := range s.Requests() {
+		if r.Method == method {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// File returns the content uploaded for a file ID.
+func (s *Server) File(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.files[id]
+	return content, ok
+}
+
+// Reset forgets recorded requests, scripted failures and stored messages.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.failures = map[string][]Failure{}
+	s.messages = map[string]bool{}
+	s.reactions = map[reaction]bool{}
+	s.files = map[string][]byte{}
+}
+
+// serveHTTP records a request and answers it.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	req := Request{Path: r.URL.Path, Header: r.Header.Clone(), Raw: raw}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/"):
+		req.Method = strings.TrimPrefix(r.URL.Path, "/api/")
+	case strings.HasPrefix(r.URL.Path, "/services/"), strings.HasPrefix(r.URL.Path, "/actions/"):
+		req.Method = Webhook
+	case strings.HasPrefix(r.URL.Path, "/upload/"):
+		req.Method = "upload"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	req.Body = decodeBody(r.Header.Get("Content-Type"), raw)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	failure, failed := s.nextFailure(req.Method)
+	s.mu.Unlock()
+
+	switch {
+	case failed && req.Method == Webhook:
+		writeWebhookFailure(w, failure)
+	case failed:
+		writeAPIFailure(w, failure)
+	case req.Method == Webhook:
+		s.serveWebhook(w, req)
+	case req.Method == "upload":
+		s.serveUpload(w, req)
+	default:
+		s.serveAPI(w, r, req)
+	}
+}
+
+// nextFailure pops the next scripted failure for method. s.mu must be held.
+func (s *Server) nextFailure(method string) (Failure, bool) {
+	queue := s.failures[method]
+	if len(queue) == 0 {
+		return Failure{}, false
+	}
+	s.failures[method] = queue[1:]
+	return queue[0], true
+}
+
+// decodeBody decodes a JSON or form-encoded body.
+func decodeBody(contentType string, raw []byte) map[string]any {
+	body := map[string]any{}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		values, _ := url.ParseQuery(string(raw))
+		for k := range values {
+			body[k] = values.Get(k)
+		}
+		return body
+	}
+	_ = json.Unmarshal(raw, &body)
+	return body
+}
+
+// writeWebhookFailure answers a webhook with a plain-text error, as Slack does.
+func writeWebhookFailure(w http.ResponseWriter, f Failure) {
+	status := f.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	setRetryAfter(w, f)
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, f.Error)
+}
+
+// writeAPIFailure answers a Web API call with ok=false.
+func writeAPIFailure(w http.ResponseWriter, f Failure) {
+	status := f.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	setRetryAfter(w, f)
+	writeJSON(w, status, map[string]any{"ok": false, "error": f.Error})
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds, rounded up.
+func setRetryAfter(w http.ResponseWriter, f Failure) {
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+	}
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// serveWebhook accepts messages with text, blocks or attachments.
+func (s *Server) serveWebhook(w http.ResponseWriter, req Request) {
+	if !json.Valid(req.Raw) {
+		writeWebhookFailure(w, InvalidPayload())
+		return
+	}
+	if !hasContent(req.Body) {
+		writeWebhookFailure(w, Failure{Error: "no_text"})
+		return
+	}
+	_, _ = io.WriteString(w, "ok")
+}
+
+// hasContent reports whether a message has something to show.
+func hasContent(body map[string]any) bool {
+	for _, key := range []string{"text", "blocks", "attachments"} {
+		switch v := body[key].(type) {
+		case string:
+			if v != "" {
+				return true
+			}
+		case []any:
+			if len(v) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveUpload stores uploaded file content.
+func (s *Server) serveUpload(w http.ResponseWriter, req Request) {
+	id := strings.TrimPrefix(req.Path, "/upload/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[id]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.files[id] = req.Raw
+	_, _ = io.WriteString(w, "OK - "+strconv.Itoa(len(req.Raw)))
+}
+
+// serveAPI answers a Web API call.
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request, req Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer xox") {
+		writeAPIFailure(w, APIError("not_authed"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resp, ok := s.responses[req.Method]; ok {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	str := func(key string) string {
+		v, _ := req.Body[key].(string)
+		return v
+	}
+
+	switch req.Method {
+	case "auth.test":
+		w.Header().Set("X-OAuth-Scopes", s.scopes)
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "user_id": "U0SLACKTEST", "team_id": "T00000000", "bot_id": "B00000000"})
+
+	case "chat.postMessage", "chat.scheduleMessage":
+		if !hasContent(req.Body) {
+			writeAPIFailure(w, APIError("no_text"))
+			return
+		}
+		channel := s.channelID(str("channel"))
+		if channel == "" {
+			writeAPIFailure(w, APIError("channel_not_found"))
+			return
+		}
+		ts := s.nextTS()
+		s.messages[channel+"/"+ts] = true
+		resp := map[string]any{"ok": true, "channel": channel, "ts": ts}
+		if req.Method == "chat.scheduleMessage" {
+			resp = map[string]any{"ok": true, "channel": channel, "scheduled_message_id": "Q" + ts, "post_at": req.Body["post_at"]}
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case "chat.update":
+		channel := s.channelID(str("channel"))
+		if !s.messages[channel+"/"+str("ts")] {
+			writeAPIFailure(w, APIError("message_not_found"))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "channel": channel, "ts": str("ts")})
+
+	case "chat.getPermalink":
+		channel := s.channelID(str("channel"))
+		ts := str("message_ts")
+		if !s.messages[channel+"/"+ts] {
+			writeAPIFailure(w, APIError("message_not_found"))
+			return
+		}
+		link := fmt.Sprintf("%s/archives/%s/p%s", s.URL, channel, strings.ReplaceAll(ts, ".", ""))
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "channel": channel, "permalink": link})
+
+	case "reactions.add", "reactions.remove":
+		channel := s.channelID(str("channel"))
+		key := reaction{channel: channel, ts: str("timestamp"), name: str("name")}
+		switch {
+		case !s.messages[channel+"/"+key.ts]:
+			writeAPIFailure(w, APIError("message_not_found"))
+		case req.Method == "reactions.add" && s.reactions[key]:
+			writeAPIFailure(w, APIError("already_reacted"))
+		case req.Method == "reactions.remove" && !s.reactions[key]:
+			writeAPIFailure(w, APIError("no_reaction"))
+		default:
+			s.reactions[key] = req.Method == "reactions.add"
+			if !s.reactions[key] {
+				delete(s.reactions, key)
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+		}
+
+	case "files.getUploadURLExternal":
+		if str("filename") == "" || str("length") == "" {
+			writeAPIFailure(w, APIError("invalid_arguments"))
+			return
+		}
+		s.lastID++
+		id := fmt.Sprintf("F%08d", s.lastID)
+		s.files[id] = nil
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "upload_url": s.URL + "/upload/" + id, "file_id": id})
+
+	case "files.completeUploadExternal":
+		files, _ := req.Body["files"].([]any)
+		if len(files) == 0 {
+			writeAPIFailure(w, APIError("invalid_arguments"))
+			return
+		}
+		for _, f := range files {
+			entry, _ := f.(map[string]any)
+			id, _ := entry["id"].(string)
+			if s.files[id] == nil {
+				writeAPIFailure(w, APIError("file_not_found"))
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "files": files})
+
+	case "users.lookupByEmail":
+		id, ok := s.users[strings.ToLower(str("email"))]
+		if !ok {
+			writeAPIFailure(w, APIError("users_not_found"))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "user": map[string]any{"id": id}})
+
+	case "conversations.info":
+		channel := s.channelID(str("channel"))
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "channel": map[string]any{
+			"id": channel, "is_member": true, "topic": map[string]any{"value": ""},
+		}})
+
+	default:
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	}
+}
+
+// channelID resolves a channel name to a stable fake ID. IDs pass through.
+// s.mu must be held.
+func (s *Server) channelID(channel string) string {
+	if channel == "" {
+		return ""
+	}
+	if !strings.HasPrefix(channel, "#") {
+		return channel
+	}
+	if id, ok := s.channels[channel]; ok {
+		return id
+	}
+	id := fmt.Sprintf("C%08d", len(s.channels)+1)
+	s.channels[channel] = id
+	return id
+}
+
+// nextTS returns a new, increasing message timestamp. s.mu must be held.
+func (s *Server) nextTS() string {
+	s.lastTS++
+	return fmt.Sprintf("%d.000100", s.lastTS)
+}
@@ -0,0 +1,178 @@
+// Package slacktest provides tests for the fake Slack server.
+package slacktest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// post sends a request to the fake and returns the status, Retry-After header and body.
+func post(t *testing.T, s *Server, path, contentType, body string) (int, string, string) {
+	t.Helper()
+	req, err := http.NewRequest("POST", s.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer xoxb-test")
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get("Retry-After"), strings.TrimSpace(string(data))
+}
+
+// call invokes a Web API method with a JSON body and decodes the response.
+func call(t *testing.T, s *Server, method, body string) map[string]any {
+	t.Helper()
+	_, _, data := post(t, s, "/api/"+method, "application/json", body)
+	var out map[string]any
+	if err := json.Unmarshal([]byte(data), &out); err != nil {
+		t.Fatalf("%s: invalid response %q", method, data)
+	}
+	return out
+}
+
+// TestWebhook tests incoming webhooks and scripted failures.
+func TestWebhook(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	tests := []struct {
+		body       string
+		failure    *Failure
+		status     int
+		retryAfter string
+		response   string
+	}{
+		{body: `{"text":"hi"}`, status: 200, response: "ok"},
+		{body: `{"attachments":[{"title":"hi"}]}`, status: 200, response: "ok"},
+		{body: `{"channel":"#x"}`, status: 400, response: "no_text"},
+		{body: `{"text":`, status: 400, response: "invalid_payload"},
+		{body: `{"text":"hi"}`, failure: &Failure{Error: "invalid_payload"}, status: 400, response: "invalid_payload"},
+		{body: `{"text":"hi"}`, failure: ptr(RateLimited(1500 * time.Millisecond)), status: 429, retryAfter: "2", response: "ratelimited"},
+		{body: `{"text":"hi"}`, failure: ptr(ServerError()), status: 500, response: "internal_error"},
+	}
+	for _, tt := range tests {
+		if tt.failure != nil {
+			s.FailNext(Webhook, *tt.failure)
+		}
+		status, retryAfter, body := post(t, s, "/services/T1/B1/x", "application/json", tt.body)
+		if status != tt.status || retryAfter != tt.retryAfter || body != tt.response {
+			t.Errorf("%s: expected %d %q %q, got %d %q %q", tt.body, tt.status, tt.retryAfter, tt.response, status, retryAfter, body)
+		}
+	}
+
+	calls := s.Calls(Webhook)
+	if len(calls) != len(tests) || calls[0].Body["text"] != "hi" || calls[0].Path != "/services/T1/B1/x" {
+		t.Errorf("expected every request to be recorded, got %+v", calls)
+	}
+	s.Reset()
+	if len(s.Requests()) != 0 {
+		t.Error("expected Reset to forget requests")
+	}
+}
+
+// ptr returns a pointer to f.
+func ptr(f Failure) *Failure {
+	return &f
+}
+
+// TestWebAPI tests the Web API methods.
+func TestWebAPI(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	posted := call(t, s, "chat.postMessage", `{"channel":"#releases","text":"hi"}`)
+	if posted["ok"] != true || posted["channel"] != "C00000001" || posted["ts"] != "1700000001.000100" {
+		t.Fatalf("unexpected chat.postMessage response %v", posted)
+	}
+	if again := call(t, s, "chat.postMessage", `{"channel":"#releases","text":"again"}`); again["channel"] != "C00000001" {
+		t.Errorf("expected a stable channel ID, got %v", again["channel"])
+	}
+
+	updated := call(t, s, "chat.update", `{"channel":"C00000001","ts":"1700000001.000100","text":"edited"}`)
+	if updated["ok"] != true {
+		t.Errorf("unexpected chat.update response %v", updated)
+	}
+	if missing := call(t, s, "chat.update", `{"channel":"C00000001","ts":"1.0","text":"edited"}`); missing["error"] != "message_not_found" {
+		t.Errorf("expected message_not_found, got %v", missing)
+	}
+
+	reaction := `{"channel":"C00000001","timestamp":"1700000001.000100","name":"rocket"}`
+	for _, step := range []struct{ method, err string }{
+		{"reactions.add", ""},
+		{"reactions.add", "already_reacted"},
+		{"reactions.remove", ""},
+		{"reactions.remove", "no_reaction"},
+	} {
+		got := call(t, s, step.method, reaction)
+		if errCode, _ := got["error"].(string); errCode != step.err {
+			t.Errorf("%s: expected error %q, got %v", step.method, step.err, got)
+		}
+	}
+
+	s.AddUser("Dev@Example.com", "U123")
+	if user := call(t, s, "users.lookupByEmail", `{"email":"dev@example.com"}`); user["user"].(map[string]any)["id"] != "U123" {
+		t.Errorf("unexpected users.lookupByEmail response %v", user)
+	}
+	if user := call(t, s, "users.lookupByEmail", `{"email":"nobody@example.com"}`); user["error"] != "users_not_found" {
+		t.Errorf("expected users_not_found, got %v", user)
+	}
+
+	s.FailNext("chat.postMessage", RateLimited(time.Second), APIError("channel_not_found"))
+	status, retryAfter, _ := post(t, s, "/api/chat.postMessage", "application/json", `{"channel":"#x","text":"hi"}`)
+	if status != 429 || retryAfter != "1" {
+		t.Errorf("expected 429 with Retry-After, got %d %q", status, retryAfter)
+	}
+	if failed := call(t, s, "chat.postMessage", `{"channel":"#x","text":"hi"}`); failed["error"] != "channel_not_found" {
+		t.Errorf("expected the second scripted failure, got %v", failed)
+	}
+
+	s.SetResponse("auth.test", map[string]any{"ok": false, "error": "invalid_auth"})
+	if auth := call(t, s, "auth.test", `{}`); auth["error"] != "invalid_auth" {
+		t.Errorf("expected the scripted response, got %v", auth)
+	}
+}
+
+// TestFileUploads tests the external upload flow.
+func TestFileUploads(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	form := url.Values{"filename": {"notes.md"}, "length": {"5"}}.Encode()
+	_, _, data := post(t, s, "/api/files.getUploadURLExternal", "application/x-www-form-urlencoded", form)
+	var reserved struct {
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}
+	if err := json.Unmarshal([]byte(data), &reserved); err != nil || reserved.FileID == "" {
+		t.Fatalf("unexpected response %q", data)
+	}
+	if got := s.Calls("files.getUploadURLExternal")[0].Body["filename"]; got != "notes.md" {
+		t.Errorf("expected form values to be recorded, got %v", got)
+	}
+
+	complete := `{"files":[{"id":"` + reserved.FileID + `"}],"channel_id":"C1"}`
+	if early := call(t, s, "files.completeUploadExternal", complete); early["error"] != "file_not_found" {
+		t.Errorf("expected completion before upload to fail, got %v", early)
+	}
+
+	status, _, _ := post(t, s, strings.TrimPrefix(reserved.UploadURL, s.URL), "application/octet-stream", "hello")
+	if status != 200 {
+		t.Fatalf("upload failed with %d", status)
+	}
+	if content, ok := s.File(reserved.FileID); !ok || string(content) != "hello" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if done := call(t, s, "files.completeUploadExternal", complete); done["ok"] != true {
+		t.Errorf("unexpected completion %v", done)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+const (
+	// stateFileName is the name of the message state file inside the state directory.
+	stateFileName = "messages.json"
+	// maxMessageRecords is how many releases the state file remembers. The
+	// records of older releases are dropped, oldest announcement first.
+	maxMessageRecords = 50
+)
+
+// stateMu serializes read-modify-write cycles on the state file within the process.
+var stateMu sync.Mutex
+
+// messageRecord identifies the announcement posted for a release.
+type messageRecord struct {
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+	// Reaction is the state reaction currently on the message.
+	Reaction string `json:"reaction,omitempty"`
+	// Files are the IDs of the release files shared in the message's thread.
+	Files []string `json:"files,omitempty"`
+	// ChannelUpdated is set once the channel topic and bookmark point at the release.
+	ChannelUpdated bool `json:"channel_updated,omitempty"`
+}
+
+// messageState is the persisted state shared across hook invocations.
+type messageState struct {
+	Messages map[string]*messageRecord `json:"messages"`
+}
+
+// releaseKey identifies a release in persisted state.
+func releaseKey(releaseCtx plugin.ReleaseContext) string {
+	if releaseCtx.Version != "" {
+		return releaseCtx.Version
+	}
+	return releaseCtx.TagName
+}
+
+// loadState reads the message state from dir. A missing file yields empty state.
+func loadState(dir string) (*messageState, error) {
+	st := &messageState{Messages: map[string]*messageRecord{}}
+
+	data, err := os.ReadFile(filepath.Join(dir, stateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to decode state: %w", err)
+	}
+	if st.Messages == nil {
+		st.Messages = map[string]*messageRecord{}
+	}
+	return st, nil
+}
+
+// saveState atomically writes the message state to dir.
+func saveState(dir string, st *messageState) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, stateFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, stateFileName)); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// readMessageRecord returns a copy of the record for key, or nil when none
+// exists. The copy may be used without holding stateMu, such as while
+// calling Slack.
+func readMessageRecord(dir, key string) (*messageRecord, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	st, err := loadState(dir)
+	if err != nil {
+		return nil, err
+	}
+	rec, ok := st.Messages[key]
+	if !ok || rec == nil {
+		return nil, nil
+	}
+	out := *rec
+	out.Files = slices.Clone(rec.Files)
+	return &out, nil
+}
+
+// updateMessageRecord loads state, applies fn to the record for key and saves
+// the result. fn receives nil when no record exists and returns the record to
+// store, or nil to leave state unchanged. fn runs under stateMu and must not
+// call Slack. Saving drops the records beyond maxMessageRecords.
+func updateMessageRecord(dir, key string, fn func(*messageRecord) *messageRecord) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	st, err := loadState(dir)
+	if err != nil {
+		return err
+	}
+
+	rec := fn(st.Messages[key])
+	if rec == nil {
+		return nil
+	}
+	st.Messages[key] = rec
+	st.prune(key)
+	return saveState(dir, st)
+}
+
+// prune drops the records of the oldest announcements, by their Slack
+// timestamp, until at most maxMessageRecords remain. keep is never dropped.
+func (st *messageState) prune(keep string) {
+	if len(st.Messages) <= maxMessageRecords {
+		return
+	}
+	keys := make([]string, 0, len(st.Messages))
+	for k := range st.Messages {
+		if k != keep {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return st.Messages[keys[i]].posted() < st.Messages[keys[j]].posted()
+	})
+	for _, k := range keys[:len(st.Messages)-maxMessageRecords] {
+		delete(st.Messages, k)
+	}
+}
+
+// posted returns the Slack timestamp of the record's message as seconds.
+// Records without a valid timestamp sort first.
+func (r *messageRecord) posted() float64 {
+	if r == nil {
+		return 0
+	}
+	ts, err := strconv.ParseFloat(r.TS, 64)
+	if err != nil {
+		return 0
+	}
+	return ts
+}
@@ -0,0 +1,130 @@
+// Package main provides tests for persisted message state.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestMessageState tests loading, saving and updating message records.
+func TestMessageState(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested")
+
+	st, err := loadState(dir)
+	if err != nil {
+		t.Fatalf("unexpected error loading missing state: %v", err)
+	}
+	if len(st.Messages) != 0 {
+		t.Errorf("expected empty state, got %v", st.Messages)
+	}
+
+	err = updateMessageRecord(dir, "1.0.0", func(rec *messageRecord) *messageRecord {
+		if rec != nil {
+			t.Errorf("expected no record, got %+v", rec)
+		}
+		return &messageRecord{Channel: "C1", TS: "1.1"}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = updateMessageRecord(dir, "1.0.0", func(rec *messageRecord) *messageRecord {
+		if rec == nil || rec.TS != "1.1" {
+			t.Errorf("expected stored record, got %+v", rec)
+			return nil
+		}
+		rec.Reaction = "hourglass"
+		return rec
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	st, err = loadState(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := st.Messages["1.0.0"]; got == nil || got.Channel != "C1" || got.Reaction != "hourglass" {
+		t.Errorf("unexpected record %+v", got)
+	}
+
+	t.Run("nil update leaves state untouched", func(t *testing.T) {
+		if err := updateMessageRecord(dir, "2.0.0", func(*messageRecord) *messageRecord { return nil }); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		st, _ := loadState(dir)
+		if _, ok := st.Messages["2.0.0"]; ok {
+			t.Error("expected no record for 2.0.0")
+		}
+	})
+
+	t.Run("copies are not shared", func(t *testing.T) {
+		rec, err := readMessageRecord(dir, "1.0.0")
+		if err != nil || rec == nil {
+			t.Fatalf("expected the stored record, got %+v, %v", rec, err)
+		}
+		rec.Reaction = "boom"
+		if again, _ := readMessageRecord(dir, "1.0.0"); again.Reaction != "hourglass" {
+			t.Errorf("expected the stored record to be unchanged, got %+v", again)
+		}
+		if missing, err := readMessageRecord(dir, "9.9.9"); missing != nil || err != nil {
+			t.Errorf("expected no record, got %+v, %v", missing, err)
+		}
+	})
+
+	t.Run("old releases are pruned", func(t *testing.T) {
+		dir := t.TempDir()
+		for i := 1; i <= maxMessageRecords+5; i++ {
+			err := updateMessageRecord(dir, fmt.Sprintf("1.%d.0", i), func(*messageRecord) *messageRecord {
+				return &messageRecord{Channel: "C1", TS: fmt.Sprintf("%d.000100", 1700000000+i)}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The updated release is kept even when it is the oldest.
+		err := updateMessageRecord(dir, "1.1.0", func(*messageRecord) *messageRecord {
+			return &messageRecord{Channel: "C1", TS: "1700000001.000100"}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		st, _ := loadState(dir)
+		if len(st.Messages) != maxMessageRecords {
+			t.Errorf("expected %d records, got %d", maxMessageRecords, len(st.Messages))
+		}
+		for _, key := range []string{"1.1.0", "1.7.0", "1.55.0"} {
+			if _, ok := st.Messages[key]; !ok {
+				t.Errorf("expected %s to be kept", key)
+			}
+		}
+		if _, ok := st.Messages["1.6.0"]; ok {
+			t.Error("expected the oldest releases to be dropped")
+		}
+	})
+
+	t.Run("corrupt state", func(t *testing.T) {
+		bad := t.TempDir()
+		if err := os.WriteFile(filepath.Join(bad, stateFileName), []byte("{"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadState(bad); err == nil {
+			t.Error("expected decode error")
+		}
+	})
+}
+
+// TestReleaseKey tests the state key derived from a release context.
+func TestReleaseKey(t *testing.T) {
+	if got := releaseKey(plugin.ReleaseContext{Version: "1.0.0", TagName: "v1.0.0"}); got != "1.0.0" {
+		t.Errorf("expected version key, got %q", got)
+	}
+	if got := releaseKey(plugin.ReleaseContext{TagName: "v1.0.0"}); got != "v1.0.0" {
+		t.Errorf("expected tag key, got %q", got)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/message"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Default templates for the opt-in lifecycle announcements.
+const (
+	defaultStartTemplate = `:hourglass_flowing_sand: {{if .Repository}}{{t "Release starting for *%s* on ` + "`%s`" + `" .Repository .Branch}}` +
+		`{{else}}{{t "Release starting on ` + "`%s`" + `" .Branch}}{{end}}`
+
+	defaultPlanTemplate = `:memo: {{t "*Release %s planned*" .Version}}{{if .ReleaseType}} ({{.ReleaseTypeName}}){{end}}{{if .PreviousVersion}}, {{t "following %s" .PreviousVersion}}{{end}}
+{{- if .Summary}}
+{{.Summary}}{{end}}
+{{- with .Changes}}
+{{- range .Breaking}}
+• :warning: {{.Description}}{{end}}
+{{- range .Features}}
+• {{.Description}}{{end}}
+{{- range .Fixes}}
+• {{.Description}}{{end}}
+{{- end}}`
+
+	defaultPublishingTemplate = `:package: {{if .TagName}}{{t "Publishing release *%s* (` + "`%s`" + `) now" .Version .TagName}}` +
+		`{{else}}{{t "Publishing release *%s* now" .Version}}{{end}}`
+)
+
+// templateConfigKeys lists the config keys holding message templates.
+var templateConfigKeys = []string{
+	"start_template", "plan_template", "publishing_template",
+	"channel_topic_template", "channel_bookmark_template", "channel_bookmark_link",
+	"schedule_at", "prerelease_template", "release_url", "downloads_url",
+}
+
+// mrkdwnEscaper escapes the characters Slack treats as control sequences.
+var mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// templateData is the data available to message templates.
+// All release context strings are escaped, so commit messages cannot inject
+// mentions or links; literal text in the template itself is left untouched.
+type templateData struct {
+	plugin.ReleaseContext
+	// Repository is "owner/name" when both are known.
+	Repository string
+	// Summary is the change count summary, e.g. "2 features, 1 fixes".
+	Summary string
+	// ReleaseTypeName is the release type in the printer's language.
+	ReleaseTypeName string
+	// ReleaseURL is the release page: release_url, or derived from the
+	// repository URL and tag.
+	ReleaseURL string
+	// DownloadsURL is the rendered downloads_url.
+	DownloadsURL string
+	// Assets are the rendered assets.
+	Assets []assetLink
+	// Vars are the configured vars; missing keys render empty.
+	Vars map[string]string
+	// Stability is "stable", "prerelease" or "build".
+	Stability string
+	// PrereleaseChannel is the prerelease channel, such as "rc" or "nightly".
+	PrereleaseChannel string
+	// PromotedFrom is the prerelease a stable release was promoted from.
+	PromotedFrom string
+}
+
+// newTemplateData builds escaped template data for a release context.
+func newTemplateData(pr *message.Printer, releaseCtx plugin.ReleaseContext) templateData {
+	data := newPlainTemplateData(pr, escapeReleaseContext(releaseCtx))
+	data.Stability = releaseStability(releaseCtx)
+	data.PrereleaseChannel = prereleaseChannel(releaseCtx)
+	data.PromotedFrom = mrkdwnEscaper.Replace(promotedFrom(releaseCtx))
+	return data
+}
+
+// newPlainTemplateData builds unescaped template data for a release context,
+// for links and other text that is not mrkdwn.
+func newPlainTemplateData(pr *message.Printer, rc plugin.ReleaseContext) templateData {
+	repo := rc.RepositoryName
+	if rc.RepositoryOwner != "" && rc.RepositoryName != "" {
+		repo = rc.RepositoryOwner + "/" + rc.RepositoryName
+	}
+
+	return templateData{
+		ReleaseContext:  rc,
+		Repository:      repo,
+		Summary:         changeSummary(pr, rc.Changes),
+		ReleaseTypeName: releaseTypeName(pr, rc.ReleaseType),
+		ReleaseURL:      releaseURL(rc),
+
+		Stability:         releaseStability(rc),
+		PrereleaseChannel: prereleaseChannel(rc),
+		PromotedFrom:      promotedFrom(rc),
+	}
+}
+
+// releaseURL returns the GitHub-style release page for a tag, or "" when the
+// repository URL or tag is unknown.
+func releaseURL(rc plugin.ReleaseContext) string {
+	if rc.RepositoryURL == "" || rc.TagName == "" {
+		return ""
+	}
+	base := strings.TrimSuffix(strings.TrimSuffix(rc.RepositoryURL, "/"), ".git")
+	return base + "/releases/tag/" + rc.TagName
+}
+
+// changeSummary returns the localized "N features, N fixes" summary, or ""
+// without changes.
+func changeSummary(pr *message.Printer, changes *plugin.CategorizedChanges) string {
+	if changes == nil {
+		return ""
+	}
+	summary := translate(pr, "%d features", len(changes.Features)) + ", " + translate(pr, "%d fixes", len(changes.Fixes))
+	if breaking := len(changes.Breaking); breaking > 0 {
+		summary += ", " + translate(pr, "%d breaking changes", breaking)
+	}
+	return summary
+}
+
+// escapeReleaseContext returns a copy of rc with every string escaped for mrkdwn.
+func escapeReleaseContext(rc plugin.ReleaseContext) plugin.ReleaseContext {
+	e := mrkdwnEscaper.Replace
+	out := rc
+	out.Version = e(rc.Version)
+	out.PreviousVersion = e(rc.PreviousVersion)
+	out.TagName = e(rc.TagName)
+	out.ReleaseType = e(rc.ReleaseType)
+	out.RepositoryURL = e(rc.RepositoryURL)
+	out.RepositoryOwner = e(rc.RepositoryOwner)
+	out.RepositoryName = e(rc.RepositoryName)
+	out.Branch = e(rc.Branch)
+	out.CommitSHA = e(rc.CommitSHA)
+	out.Changelog = e(rc.Changelog)
+	out.ReleaseNotes = e(rc.ReleaseNotes)
+
+	if rc.Changes != nil {
+		c := *rc.Changes
+		c.Features = escapeCommits(rc.Changes.Features)
+		c.Fixes = escapeCommits(rc.Changes.Fixes)
+		c.Breaking = escapeCommits(rc.Changes.Breaking)
+		c.Performance = escapeCommits(rc.Changes.Performance)
+		c.Refactor = escapeCommits(rc.Changes.Refactor)
+		c.Docs = escapeCommits(rc.Changes.Docs)
+		c.Other = escapeCommits(rc.Changes.Other)
+		out.Changes = &c
+	}
+
+	if rc.Environment != nil {
+		out.Environment = make(map[string]string, len(rc.Environment))
+		for k, v := range rc.Environment {
+			out.Environment[k] = e(v)
+		}
+	}
+	return out
+}
+
+// escapeCommits returns escaped copies of commits.
+func escapeCommits(commits []plugin.ConventionalCommit) []plugin.ConventionalCommit {
+	if commits == nil {
+		return nil
+	}
+	e := mrkdwnEscaper.Replace
+	out := make([]plugin.ConventionalCommit, len(commits))
+	for i, c := range commits {
+		c.Scope = e(c.Scope)
+		c.Description = e(c.Description)
+		c.Body = e(c.Body)
+		c.BreakingDescription = e(c.BreakingDescription)
+		c.Author = e(c.Author)
+		out[i] = c
+	}
+	return out
+}
+
+// parseMessageTemplate parses a message template.
+// Missing map keys (e.g. unset environment variables) render as empty strings;
+// unknown fields still fail, so typos surface at validation time.
+// The t function translates a message with pr: {{t "following %s" .Version}}.
+func parseMessageTemplate(pr *message.Printer, name, text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"t": func(key string, args ...any) string { return translate(pr, key, args...) },
+	}
+	return template.New(name).Option("missingkey=zero").Funcs(funcs).Parse(text)
+}
+
+// renderTemplate renders a message template for a release context in the
+// printer's language.
+func renderTemplate(pr *message.Printer, name, text string, releaseCtx plugin.ReleaseContext) (string, error) {
+	return executeTemplate(pr, name, text, newTemplateData(pr, releaseCtx))
+}
+
+// executeTemplate renders a message template with data.
+func executeTemplate(pr *message.Printer, name, text string, data templateData) (string, error) {
+	tmpl, err := parseMessageTemplate(pr, name, text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// sampleReleaseContext is used to check that templates render at validation time.
+var sampleReleaseContext = plugin.ReleaseContext{
+	Version:         "1.2.3",
+	PreviousVersion: "1.2.2",
+	TagName:         "v1.2.3",
+	ReleaseType:     "minor",
+	RepositoryURL:   "https://github.com/example/project",
+	RepositoryOwner: "example",
+	RepositoryName:  "project",
+	Branch:          "main",
+	CommitSHA:       "abc1234",
+	ReleaseNotes:    "## What's Changed",
+	Changes: &plugin.CategorizedChanges{
+		Features: []plugin.ConventionalCommit{{Hash: "abc1234", Type: "feat", Description: "Add feature"}},
+		Fixes:    []plugin.ConventionalCommit{{Hash: "def5678", Type: "fix", Description: "Fix bug"}},
+	},
+	Environment: map[string]string{},
+}
+
+// validateTemplate reports parse and render errors for a configured template.
+func validateTemplate(name, text string) error {
+	_, err := renderTemplate(newPrinter(""), name, text, sampleReleaseContext)
+	return err
+}
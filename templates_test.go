@@ -0,0 +1,72 @@
+// Package main provides tests for message templates.
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestRenderTemplate tests template rendering and escaping of release data.
+func TestRenderTemplate(t *testing.T) {
+	releaseCtx := plugin.ReleaseContext{
+		Version:     "2.0.0",
+		Branch:      "feature/<b>",
+		Environment: map[string]string{"DEPLOY_ENV": "prod & staging"},
+		Changes: &plugin.CategorizedChanges{
+			Breaking: []plugin.ConventionalCommit{{Description: "Drop <@U1> support", BreakingDescription: "see <https://evil>"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "plain fields", text: "v{{.Version}}", want: "v2.0.0"},
+		{name: "escaped branch", text: "{{.Branch}}", want: "feature/&lt;b&gt;"},
+		{name: "escaped commits", text: "{{range .Changes.Breaking}}{{.Description}}|{{.BreakingDescription}}{{end}}", want: "Drop &lt;@U1&gt; support|see &lt;https://evil&gt;"},
+		{name: "environment", text: "{{.Environment.DEPLOY_ENV}}", want: "prod &amp; staging"},
+		{name: "missing environment key", text: "[{{.Environment.MISSING}}]", want: "[]"},
+		{name: "summary", text: "{{.Summary}}", want: "0 features, 0 fixes, 1 breaking change"},
+		{name: "literal mentions kept", text: "<!here> {{.Version}}", want: "<!here> 2.0.0"},
+		{name: "unknown field", text: "{{.Verison}}", wantErr: "can't evaluate field Verison"},
+		{name: "parse error", text: "{{if}}", wantErr: "invalid test_template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTemplate(newPrinter(""), "test_template", tt.text, releaseCtx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestDefaultTemplatesRender ensures the built-in templates render without changes data.
+func TestDefaultTemplatesRender(t *testing.T) {
+	for name, text := range map[string]string{
+		"start_template":      defaultStartTemplate,
+		"plan_template":       defaultPlanTemplate,
+		"publishing_template": defaultPublishingTemplate,
+	} {
+		if err := validateTemplate(name, text); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if _, err := renderTemplate(newPrinter(""), name, text, plugin.ReleaseContext{}); err != nil {
+			t.Errorf("%s with empty context: unexpected error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Theme names. A release uses the first theme that applies, in this order:
+// prerelease (including builds), major, breaking (breaking changes outside a major release, as
+// in 0.x versions), then the release type; anything else uses default.
+const (
+	themePrerelease = "prerelease"
+	themeMajor      = "major"
+	themeBreaking   = "breaking"
+	themeMinor      = "minor"
+	themePatch      = "patch"
+	themeDefault    = "default"
+)
+
+// Message formats.
+const (
+	// formatAttachments sends a legacy attachment with a coloured bar.
+	formatAttachments = "attachments"
+	// formatBlocks sends Block Kit blocks inside a coloured attachment.
+	formatBlocks = "blocks"
+)
+
+// colorPattern matches the hex colours accepted for attachments.
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// namedColors are Slack's predefined attachment colours.
+var namedColors = []string{"good", "warning", "danger"}
+
+// Theme is the look of a success notification.
+type Theme struct {
+	// Color is the attachment colour: good, warning, danger or #RRGGBB.
+	Color string `json:"color,omitempty"`
+	// Emoji prefixes the title, e.g. ":tada:". Empty means no emoji.
+	Emoji string `json:"emoji,omitempty"`
+	// Title is a template for the header text.
+	Title string `json:"title,omitempty"`
+	// Mentions replaces the top-level mentions when set; empty pings nobody.
+	Mentions []string `json:"mentions,omitempty"`
+}
+
+// builtinThemes ship with the plugin; the themes option overrides their fields.
+var builtinThemes = map[string]Theme{
+	themeDefault: {
+		Color: "good",
+		Emoji: ":rocket:",
+		Title: `{{t "Release %s Published!" .Version}}`,
+	},
+	themeMajor: {
+		Color: "#6F42C1",
+		Emoji: ":tada:",
+		Title: `{{t "Major Release %s Published!" .Version}}`,
+	},
+	themeBreaking: {
+		Color: "#E8912D",
+		Emoji: ":boom:",
+		Title: `{{t "Release %s Published with Breaking Changes!" .Version}}`,
+	},
+	themeMinor: {
+		Color: "good",
+		Emoji: ":rocket:",
+		Title: `{{t "Release %s Published!" .Version}}`,
+	},
+	themePatch: {
+		Color: "#439FE0",
+		Emoji: ":adhesive_bandage:",
+		Title: `{{t "Patch Release %s Published!" .Version}}`,
+	},
+	themePrerelease: {
+		Color:    "warning",
+		Emoji:    ":test_tube:",
+		Title:    `{{t "Prerelease %s Published" .Version}}`,
+		Mentions: []string{},
+	},
+}
+
+// parseThemes parses the themes option: overrides keyed by theme name.
+func parseThemes(raw map[string]any) map[string]Theme {
+	if raw == nil {
+		return nil
+	}
+	themes := make(map[string]Theme, len(raw))
+	for name, v := range raw {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		parser := helpers.NewConfigParser(m)
+		t := Theme{
+			Color: parser.GetString("color", "", ""),
+			Emoji: parser.GetString("emoji", "", ""),
+			Title: parser.GetString("title", "", ""),
+		}
+		if parser.Has("mentions") {
+			t.Mentions = parser.GetStringSlice("mentions", []string{})
+		}
+		themes[name] = t
+	}
+	return themes
+}
+
+// themeName picks the theme for a release.
+func themeName(releaseCtx plugin.ReleaseContext) string {
+	releaseType := strings.ToLower(releaseCtx.ReleaseType)
+	switch {
+	case releaseStability(releaseCtx) != stabilityStable:
+		return themePrerelease
+	case releaseType == themeMajor:
+		return themeMajor
+	case releaseCtx.Changes != nil && len(releaseCtx.Changes.Breaking) > 0:
+		return themeBreaking
+	case releaseType == themeMinor, releaseType == themePatch:
+		return releaseType
+	default:
+		return themeDefault
+	}
+}
+
+// theme returns the theme for a release, with configured fields applied over
+// the built-in ones.
+func (c *Config) theme(releaseCtx plugin.ReleaseContext) Theme {
+	name := themeName(releaseCtx)
+	t := builtinThemes[name]
+	override, ok := c.Themes[name]
+	if !ok {
+		return t
+	}
+	if override.Color != "" {
+		t.Color = override.Color
+	}
+	if override.Emoji != "" {
+		t.Emoji = override.Emoji
+	}
+	if override.Title != "" {
+		t.Title = override.Title
+	}
+	if override.Mentions != nil {
+		t.Mentions = override.Mentions
+	}
+	return t
+}
+
+// mentions returns who the theme pings: its own mentions if set, otherwise
+// the configured ones.
+func (t Theme) mentions(cfg *Config) []string {
+	if t.Mentions != nil {
+		return t.Mentions
+	}
+	return cfg.Mentions
+}
+
+// title renders the header text, prefixed with the theme emoji.
+func (t Theme) title(cfg *Config, releaseCtx plugin.ReleaseContext) (string, error) {
+	title, err := cfg.renderTemplate("title", t.Title, releaseCtx)
+	if err != nil {
+		return "", err
+	}
+	if t.Emoji == "" {
+		return title, nil
+	}
+	return t.Emoji + " " + title, nil
+}
+
+// validateColor checks an attachment colour.
+func validateColor(color string) error {
+	for _, name := range namedColors {
+		if color == name {
+			return nil
+		}
+	}
+	if colorPattern.MatchString(color) {
+		return nil
+	}
+	return fmt.Errorf("color %q must be good, warning, danger or a hex colour such as #439FE0", color)
+}
+
+// validateThemes checks the themes option. Unknown theme names are reported
+// by the schema check.
+func validateThemes(vb, warnings *helpers.ValidationBuilder, config map[string]any) {
+	themes := parseThemes(helpers.NewConfigParser(config).GetMap("themes"))
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := themes[name]
+		path := "themes." + name
+		if t.Color != "" {
+			if err := validateColor(t.Color); err != nil {
+				vb.AddErrorWithCode(path+".color", err.Error(), "format")
+			}
+		}
+		if t.Emoji != "" && !emojiPattern.MatchString(t.Emoji) {
+			vb.AddErrorWithCode(path+".emoji",
+				fmt.Sprintf("emoji %q must be an emoji short code such as :tada:", t.Emoji),
+				"format")
+		}
+		if t.Title != "" {
+			if err := validateTemplate(path+".title", t.Title); err != nil {
+				vb.AddErrorWithCode(path+".title", err.Error(), "template")
+			}
+		}
+		for i, m := range t.Mentions {
+			if err := validateMention(m); err != nil {
+				warnings.AddErrorWithCode(fmt.Sprintf("%s.mentions[%d]", path, i), err.Error(), warningCodePrefix+"mention_format")
+			}
+		}
+	}
+}
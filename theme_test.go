@@ -0,0 +1,130 @@
+// Package main provides tests for release themes and the blocks format.
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestThemeName tests how releases are matched to themes.
+func TestThemeName(t *testing.T) {
+	breaking := &plugin.CategorizedChanges{Breaking: []plugin.ConventionalCommit{{Description: "drop v1 API"}}}
+
+	tests := []struct {
+		name       string
+		releaseCtx plugin.ReleaseContext
+		want       string
+	}{
+		{"major", plugin.ReleaseContext{Version: "2.0.0", ReleaseType: "major", Changes: breaking}, themeMajor},
+		{"minor", plugin.ReleaseContext{Version: "1.3.0", ReleaseType: "minor"}, themeMinor},
+		{"patch", plugin.ReleaseContext{Version: "1.3.1", ReleaseType: "Patch"}, themePatch},
+		{"breaking minor", plugin.ReleaseContext{Version: "0.5.0", ReleaseType: "minor", Changes: breaking}, themeBreaking},
+		{"prerelease", plugin.ReleaseContext{Version: "2.0.0-rc.1", ReleaseType: "major"}, themePrerelease},
+		{"build", plugin.ReleaseContext{Version: "1.3.1+build.7", ReleaseType: "patch"}, themePrerelease},
+		{"not a semantic version", plugin.ReleaseContext{Version: "2024.03-1", ReleaseType: "patch"}, themePatch},
+		{"unknown type", plugin.ReleaseContext{Version: "1.3.1"}, themeDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := themeName(tt.releaseCtx); got != tt.want {
+				t.Errorf("expected theme %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestThemedSuccessMessage tests built-in themes, overrides and mention policy.
+func TestThemedSuccessMessage(t *testing.T) {
+	cfg := (&SlackPlugin{}).parseConfig(map[string]any{
+		"webhook":  "https://hooks.slack.com/services/T/B/x",
+		"mentions": []any{"@here"},
+		"themes": map[string]any{
+			"patch": map[string]any{"color": "#000000", "title": "Hotfix {{.Version}}", "mentions": []any{"U123"}},
+			"major": map[string]any{"emoji": ":fire:"},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		version   string
+		typ       string
+		wantColor string
+		wantTitle string
+		wantText  string
+	}{
+		{"built-in minor", "1.3.0", "minor", "good", ":rocket: Release 1.3.0 Published!", "<!here>"},
+		{"overridden emoji", "2.0.0", "major", "#6F42C1", ":fire: Major Release 2.0.0 Published!", "<!here>"},
+		{"overridden patch", "1.3.1", "patch", "#000000", ":adhesive_bandage: Hotfix 1.3.1", "<@U123>"},
+		{"prerelease pings nobody", "1.4.0-beta.2", "minor", "warning", ":test_tube: Prerelease 1.4.0-beta.2 Published", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := buildSuccessMessage(cfg, plugin.ReleaseContext{Version: tt.version, ReleaseType: tt.typ}, deliveryPlan{}, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			att := msg.Attachments[0]
+			if att.Color != tt.wantColor || att.Title != tt.wantTitle || msg.Text != tt.wantText {
+				t.Errorf("expected %q/%q/%q, got %q/%q/%q",
+					tt.wantColor, tt.wantTitle, tt.wantText, att.Color, att.Title, msg.Text)
+			}
+		})
+	}
+}
+
+// TestBlocksFormat tests the Block Kit layout of a success notification.
+func TestBlocksFormat(t *testing.T) {
+	cfg := (&SlackPlugin{}).parseConfig(map[string]any{
+		"webhook":           "https://hooks.slack.com/services/T/B/x",
+		"format":            "blocks",
+		"include_changelog": true,
+		"mentions":          []any{"@channel"},
+	})
+	releaseCtx := plugin.ReleaseContext{
+		Version:      "1.3.1",
+		ReleaseType:  "patch",
+		Branch:       "fix/<urgent>",
+		ReleaseNotes: "Fixed a crash",
+		Changes:      &plugin.CategorizedChanges{Fixes: []plugin.ConventionalCommit{{Description: "crash"}}},
+	}
+
+	msg, err := buildSuccessMessage(cfg, releaseCtx, deliveryPlan{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Text != "<!channel> :adhesive_bandage: Patch Release 1.3.1 Published!" {
+		t.Errorf("unexpected fallback text %q", msg.Text)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Color != "#439FE0" || msg.Attachments[0].Title != "" {
+		t.Fatalf("expected a single coloured attachment wrapping the blocks, got %+v", msg.Attachments)
+	}
+
+	data, _ := json.Marshal(msg.Attachments[0].Blocks)
+	var blocks []struct {
+		Type   string `json:"type"`
+		Text   *TextObject
+		Fields []*TextObject
+	}
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, b := range blocks {
+		types = append(types, b.Type)
+	}
+	if got := strings.Join(types, ","); got != "header,section,section,section,context" {
+		t.Fatalf("unexpected block layout %s", got)
+	}
+	if len(blocks[1].Fields) != 4 || blocks[1].Fields[2].Text != "*Branch*\nfix/&lt;urgent&gt;" {
+		t.Errorf("expected escaped short fields, got %+v", blocks[1].Fields)
+	}
+	if blocks[2].Text.Text != "*Changes*\n0 features, 1 fix" || blocks[3].Text.Text != "Fixed a crash" {
+		t.Errorf("unexpected sections %q / %q", blocks[2].Text.Text, blocks[3].Text.Text)
+	}
+}
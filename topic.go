@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// topicSeparator joins the plugin's topic segment to the rest of the topic.
+const topicSeparator = " | "
+
+// bookmark is a channel bookmark as returned by bookmarks.list.
+type bookmark struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Link  string `json:"link"`
+}
+
+// bookmarksListResponse is the response of bookmarks.list.
+type bookmarksListResponse struct {
+	apiResponse
+	Bookmarks []bookmark `json:"bookmarks"`
+}
+
+// updatesChannel reports whether the channel topic or bookmark is managed.
+func (c *Config) updatesChannel() bool {
+	return c.ChannelTopicTemplate != "" || c.ChannelBookmarkTemplate != ""
+}
+
+// mergeTopic replaces the marked segment of topic with segment, keeping the
+// text around it. The marked segment runs from the marker to the next
+// separator or the end of the topic, so separators in segment are replaced
+// with " / " to keep it in one piece. Without a marker the segment is
+// appended.
+func mergeTopic(topic, marker, segment string) string {
+	managed := marker + " " + strings.ReplaceAll(segment, topicSeparator, " / ")
+	if i := strings.Index(topic, marker); i >= 0 {
+		rest := ""
+		if j := strings.Index(topic[i:], topicSeparator); j >= 0 {
+			rest = topic[i+j:]
+		}
+		return topic[:i] + managed + rest
+	}
+	if strings.TrimSpace(topic) == "" {
+		return managed
+	}
+	return strings.TrimRight(topic, " ") + topicSeparator + managed
+}
+
+// updateChannel points the channel topic and bookmark at the released version.
+// posted is the release announcement, whose channel ID is updated. The update
+// is recorded with the announcement, so a release that fires both
+// post-publish and on-success updates the channel once.
+func (p *SlackPlugin) updateChannel(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, posted *postedMessage) error {
+	if cfg.BotToken == "" || !cfg.updatesChannel() {
+		return nil
+	}
+	channelID := posted.Channel
+	if channelID == "" {
+		return fmt.Errorf("channel ID is unknown")
+	}
+
+	key := releaseKey(releaseCtx)
+	if key != "" {
+		rec, err := readMessageRecord(cfg.StateDir, key)
+		if err != nil {
+			return err
+		}
+		if rec != nil && rec.ChannelUpdated {
+			return nil
+		}
+	}
+
+	if cfg.ChannelTopicTemplate != "" {
+		if err := p.updateTopic(ctx, cfg, releaseCtx, channelID); err != nil {
+			return err
+		}
+	}
+	if cfg.ChannelBookmarkTemplate != "" {
+		if err := p.updateBookmark(ctx, cfg, releaseCtx, channelID); err != nil {
+			return err
+		}
+	}
+
+	if key == "" {
+		return nil
+	}
+	return updateMessageRecord(cfg.StateDir, key, func(rec *messageRecord) *messageRecord {
+		if rec == nil {
+			rec = &messageRecord{Channel: posted.Channel, TS: posted.TS}
+		}
+		rec.ChannelUpdated = true
+		return rec
+	})
+}
+
+// updateTopic sets the marked topic segment, skipping the call when the topic
+// already matches.
+func (p *SlackPlugin) updateTopic(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, channelID string) error {
+	segment, err := cfg.renderPlainTemplate("channel_topic_template", cfg.ChannelTopicTemplate, releaseCtx)
+	if err != nil {
+		return err
+	}
+
+	var info conversationsInfoResponse
+	if _, err := p.callAPI(ctx, cfg.BotToken, "conversations.info", url.Values{"channel": {channelID}}, &info); err != nil {
+		return err
+	}
+
+	topic := mergeTopic(info.Channel.Topic.Value, cfg.ChannelTopicMarker, segment)
+	if topic == info.Channel.Topic.Value {
+		return nil
+	}
+
+	_, err = p.callAPI(ctx, cfg.BotToken, "conversations.setTopic", map[string]string{
+		"channel": channelID,
+		"topic":   topic,
+	}, nil)
+	return err
+}
+
+// updateBookmark adds the release bookmark, or edits the one whose title
+// starts with the marker. Nothing is sent when it already matches.
+func (p *SlackPlugin) updateBookmark(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, channelID string) error {
+	title, err := cfg.renderPlainTemplate("channel_bookmark_template", cfg.ChannelBookmarkTemplate, releaseCtx)
+	if err != nil {
+		return err
+	}
+	title = cfg.ChannelTopicMarker + " " + title
+
+	link, err := cfg.renderPlainTemplate("channel_bookmark_link", cfg.ChannelBookmarkLink, releaseCtx)
+	if err != nil {
+		return err
+	}
+	if link == "" {
+		return fmt.Errorf("channel bookmark link is empty")
+	}
+
+	var list bookmarksListResponse
+	if _, err := p.callAPI(ctx, cfg.BotToken, "bookmarks.list", url.Values{"channel_id": {channelID}}, &list); err != nil {
+		return err
+	}
+
+	for _, b := range list.Bookmarks {
+		if !strings.HasPrefix(b.Title, cfg.ChannelTopicMarker) {
+			continue
+		}
+		if b.Title == title && b.Link == link {
+			return nil
+		}
+		_, err := p.callAPI(ctx, cfg.BotToken, "bookmarks.edit", map[string]string{
+			"bookmark_id": b.ID,
+			"channel_id":  channelID,
+			"title":       title,
+			"link":        link,
+		}, nil)
+		return err
+	}
+
+	_, err = p.callAPI(ctx, cfg.BotToken, "bookmarks.add", map[string]string{
+		"channel_id": channelID,
+		"title":      title,
+		"type":       "link",
+		"link":       link,
+	}, nil)
+	return err
+}
@@ -0,0 +1,191 @@
+// Package main provides tests for channel topic and bookmark updates.
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// TestMergeTopic tests that only the marked topic segment is replaced.
+func TestMergeTopic(t *testing.T) {
+	tests := []struct {
+		name  string
+		topic string
+		want  string
+	}{
+		{"empty topic", "", "📦 prod v1.4.0"},
+		{"appends to existing text", "Team chat ", "Team chat | 📦 prod v1.4.0"},
+		{"replaces marked segment", "Team chat | 📦 prod v1.3.0", "Team chat | 📦 prod v1.4.0"},
+		{"marker only", "📦 prod v1.3.0", "📦 prod v1.4.0"},
+		{"keeps text after the marked segment", "Team chat | 📦 prod v1.3.0 | On call: @ana", "Team chat | 📦 prod v1.4.0 | On call: @ana"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeTopic(tt.topic, "📦", "prod v1.4.0"); got != tt.want {
+				t.Errorf("mergeTopic(%q) = %q, want %q", tt.topic, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("separator in the segment", func(t *testing.T) {
+		const want = "Team chat | 📦 prod v1.4.0 / staging v1.5.0 | On call: @ana"
+		topic := "Team chat | 📦 prod v1.3.0 | On call: @ana"
+		for i := 0; i < 2; i++ {
+			if topic = mergeTopic(topic, "📦", "prod v1.4.0 | staging v1.5.0"); topic != want {
+				t.Fatalf("release %d: got %q, want %q", i+1, topic, want)
+			}
+		}
+	})
+}
+
+// TestUpdateChannel tests topic and bookmark updates after a release.
+func TestUpdateChannel(t *testing.T) {
+	releaseCtx := plugin.ReleaseContext{
+		Version:       "1.4.0",
+		TagName:       "v1.4.0",
+		RepositoryURL: "https://github.com/example/project.git",
+	}
+	newConfig := func(t *testing.T) *Config {
+		return (&SlackPlugin{}).parseConfig(map[string]any{
+			"bot_token":                 "xoxb-1-2-3",
+			"channel":                   "#releases",
+			"state_dir":                 t.TempDir(),
+			"channel_topic_template":    "prod {{.TagName}}",
+			"channel_bookmark_template": "Latest release {{.Version}}",
+		})
+	}
+	posted := &postedMessage{Channel: "C123", TS: "1700000000.000100"}
+	const link = "https://github.com/example/project/releases/tag/v1.4.0"
+
+	methods := func(calls []apiCall) map[string]apiCall {
+		out := map[string]apiCall{}
+		for _, c := range calls {
+			out[c.Method] = c
+		}
+		return out
+	}
+
+	t.Run("sets topic and adds bookmark", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, map[string]map[string]any{
+			"conversations.info": {"ok": true, "channel": map[string]any{"id": "C123", "topic": map[string]any{"value": "Team chat"}}},
+			"bookmarks.list":     {"ok": true, "bookmarks": []any{map[string]any{"id": "Bk1", "title": "Runbook", "link": "https://example.com"}}},
+		})
+
+		if err := (&SlackPlugin{}).updateChannel(context.Background(), newConfig(t), releaseCtx, posted); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := methods(*calls)
+		if topic := got["conversations.setTopic"].Body["topic"]; topic != "Team chat | 📦 prod v1.4.0" {
+			t.Errorf("unexpected topic %v", topic)
+		}
+		add, ok := got["bookmarks.add"]
+		if !ok || add.Body["title"] != "📦 Latest release 1.4.0" || add.Body["link"] != link {
+			t.Errorf("expected bookmark to be added, got %+v", add)
+		}
+	})
+
+	t.Run("skips when up to date", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, map[string]map[string]any{
+			"conversations.info": {"ok": true, "channel": map[string]any{"id": "C123", "topic": map[string]any{"value": "Team chat | 📦 prod v1.4.0"}}},
+			"bookmarks.list":     {"ok": true, "bookmarks": []any{map[string]any{"id": "Bk2", "title": "📦 Latest release 1.4.0", "link": link}}},
+		})
+
+		if err := (&SlackPlugin{}).updateChannel(context.Background(), newConfig(t), releaseCtx, posted); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := methods(*calls)
+		for _, m := range []string{"conversations.setTopic", "bookmarks.add", "bookmarks.edit"} {
+			if _, ok := got[m]; ok {
+				t.Errorf("expected %s to be skipped", m)
+			}
+		}
+	})
+
+	t.Run("text is not escaped", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, map[string]map[string]any{
+			"conversations.info": {"ok": true, "channel": map[string]any{"id": "C123"}},
+		})
+		cfg := newConfig(t)
+		cfg.ChannelTopicTemplate = "R&D {{.TagName}}"
+		cfg.ChannelBookmarkTemplate = "R&D {{.Version}}"
+		cfg.ReleaseURL = "{{.ReleaseURL}}?from=slack&tab=assets"
+
+		if err := (&SlackPlugin{}).updateChannel(context.Background(), cfg, releaseCtx, posted); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := methods(*calls)
+		if topic := got["conversations.setTopic"].Body["topic"]; topic != "📦 R&D v1.4.0" {
+			t.Errorf("unexpected topic %v", topic)
+		}
+		add := got["bookmarks.add"]
+		if add.Body["title"] != "📦 R&D 1.4.0" || add.Body["link"] != link+"?from=slack&tab=assets" {
+			t.Errorf("expected an unescaped bookmark, got %+v", add)
+		}
+	})
+
+	t.Run("edits the marked bookmark", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, map[string]map[string]any{
+			"conversations.info": {"ok": true, "channel": map[string]any{"id": "C123"}},
+			"bookmarks.list":     {"ok": true, "bookmarks": []any{map[string]any{"id": "Bk2", "title": "📦 Latest release 1.3.0", "link": "https://old"}}},
+		})
+
+		if err := (&SlackPlugin{}).updateChannel(context.Background(), newConfig(t), releaseCtx, posted); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		edit, ok := methods(*calls)["bookmarks.edit"]
+		if !ok || edit.Body["bookmark_id"] != "Bk2" || edit.Body["link"] != link {
+			t.Errorf("expected bookmark Bk2 to be edited, got %+v", edit)
+		}
+	})
+
+	t.Run("updated once per release", func(t *testing.T) {
+		calls, _ := recordingSlackAPI(t, map[string]map[string]any{
+			"conversations.info": {"ok": true, "channel": map[string]any{"id": "C123"}},
+		})
+		config := map[string]any{
+			"bot_token": "xoxb-1-2-3", "channel": "#releases", "state_dir": t.TempDir(),
+			"channel_topic_template": "prod {{.TagName}}", "channel_bookmark_template": "Latest release {{.Version}}",
+		}
+		for _, hook := range []plugin.Hook{plugin.HookPostPublish, plugin.HookOnSuccess} {
+			resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{Hook: hook, Config: config, Context: releaseCtx})
+			if err != nil || !resp.Success {
+				t.Fatalf("%s: expected success, got %v / %+v", hook, err, resp)
+			}
+		}
+
+		count := map[string]int{}
+		for _, c := range *calls {
+			count[c.Method]++
+		}
+		for _, m := range []string{"conversations.info", "conversations.setTopic", "bookmarks.list", "bookmarks.add"} {
+			if count[m] != 1 {
+				t.Errorf("expected one %s call, got %d", m, count[m])
+			}
+		}
+	})
+
+	t.Run("failure does not fail the release", func(t *testing.T) {
+		recordingSlackAPI(t, map[string]map[string]any{
+			"conversations.setTopic": {"ok": false, "error": "missing_scope"},
+		})
+
+		resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookOnSuccess,
+			Config: map[string]any{
+				"bot_token": "xoxb-1-2-3", "channel": "#releases", "state_dir": t.TempDir(),
+				"channel_topic_template": "prod {{.TagName}}",
+			},
+			Context: releaseCtx,
+		})
+		if err != nil || !resp.Success {
+			t.Errorf("expected success, got %v / %+v", err, resp)
+		}
+	})
+}
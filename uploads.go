@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// releaseFile is a file shared into the announcement thread.
+type releaseFile struct {
+	Name    string
+	Title   string
+	Content []byte
+}
+
+// artifactEntry describes one released artifact in the manifest.
+type artifactEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// artifactManifest is the JSON manifest of released artifacts.
+type artifactManifest struct {
+	Version   string          `json:"version"`
+	Tag       string          `json:"tag,omitempty"`
+	Commit    string          `json:"commit,omitempty"`
+	Artifacts []artifactEntry `json:"artifacts"`
+}
+
+// getUploadURLResponse is the response of files.getUploadURLExternal.
+type getUploadURLResponse struct {
+	apiResponse
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
+
+// uploadedFile references an uploaded file in files.completeUploadExternal.
+type uploadedFile struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+// completeUploadRequest is the body of files.completeUploadExternal.
+type completeUploadRequest struct {
+	Files     []uploadedFile `json:"files"`
+	ChannelID string         `json:"channel_id,omitempty"`
+	ThreadTS  string         `json:"thread_ts,omitempty"`
+}
+
+// releaseFiles returns the files configured for a release: the full release
+// notes as a markdown snippet and the artifacts manifest.
+func releaseFiles(cfg *Config, releaseCtx plugin.ReleaseContext) ([]releaseFile, error) {
+	var files []releaseFile
+
+	if cfg.UploadReleaseNotes && releaseCtx.ReleaseNotes != "" {
+		files = append(files, releaseFile{
+			Name:    fmt.Sprintf("release-notes-%s.md", releaseCtx.Version),
+			Title:   fmt.Sprintf("Release notes %s", releaseCtx.Version),
+			Content: []byte(releaseCtx.ReleaseNotes),
+		})
+	}
+
+	if len(cfg.Artifacts) > 0 {
+		manifest, err := buildArtifactManifest(cfg.Artifacts, releaseCtx)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, releaseFile{
+			Name:    fmt.Sprintf("artifacts-%s.json", releaseCtx.Version),
+			Title:   fmt.Sprintf("Artifacts %s", releaseCtx.Version),
+			Content: manifest,
+		})
+	}
+
+	return files, nil
+}
+
+// buildArtifactManifest expands the artifact glob patterns and returns a JSON
+// manifest with the size and SHA-256 checksum of every matching file.
+func buildArtifactManifest(patterns []string, releaseCtx plugin.ReleaseContext) ([]byte, error) {
+	seen := map[string]bool{}
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid artifact pattern %q: %w", pattern, err)
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				paths = append(paths, m)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	manifest := artifactManifest{
+		Version:   releaseCtx.Version,
+		Tag:       releaseCtx.TagName,
+		Commit:    releaseCtx.CommitSHA,
+		Artifacts: []artifactEntry{},
+	}
+	for _, path := range paths {
+		entry, err := hashArtifact(path)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			manifest.Artifacts = append(manifest.Artifacts, *entry)
+		}
+	}
+
+	return json.MarshalIndent(manifest, "", "  ")
+}
+
+// hashArtifact returns the manifest entry for path, or nil for directories.
+func hashArtifact(path string) (*artifactEntry, error) {
+	f, err := os.Open(path) // #nosec G304 -- paths come from the user's artifact patterns
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat artifact: %w", err)
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("failed to hash artifact %s: %w", path, err)
+	}
+
+	return &artifactEntry{
+		Name:   filepath.ToSlash(path),
+		Size:   info.Size(),
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// fileNames returns the names of files.
+func fileNames(files []releaseFile) []string {
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// uploadReleaseFiles uploads the configured release files and shares them
+// into the thread of the release announcement. Uploads need a bot token.
+// The shared files are recorded with the announcement, so a release that
+// fires both post-publish and on-success shares them once.
+func (p *SlackPlugin) uploadReleaseFiles(ctx context.Context, cfg *Config, releaseCtx plugin.ReleaseContext, posted *postedMessage) error {
+	if cfg.BotToken == "" {
+		return nil
+	}
+
+	key := releaseKey(releaseCtx)
+	var rec *messageRecord
+	if key != "" {
+		var err error
+		if rec, err = readMessageRecord(cfg.StateDir, key); err != nil {
+			return err
+		}
+	}
+	if rec != nil && len(rec.Files) > 0 {
+		return nil
+	}
+
+	files, err := releaseFiles(cfg, releaseCtx)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+
+	thread := announcementFor(rec, posted)
+	if thread == nil {
+		return fmt.Errorf("no announcement to attach files to")
+	}
+
+	uploaded := make([]uploadedFile, 0, len(files))
+	for _, f := range files {
+		id, err := p.uploadFile(ctx, cfg.BotToken, f)
+		if err != nil {
+			return err
+		}
+		uploaded = append(uploaded, uploadedFile{ID: id, Title: f.Title})
+	}
+
+	if _, err := p.callAPI(ctx, cfg.BotToken, "files.completeUploadExternal", completeUploadRequest{
+		Files:     uploaded,
+		ChannelID: thread.Channel,
+		ThreadTS:  thread.TS,
+	}, nil); err != nil {
+		return err
+	}
+
+	if key == "" {
+		return nil
+	}
+	return updateMessageRecord(cfg.StateDir, key, func(cur *messageRecord) *messageRecord {
+		if cur == nil {
+			cur = &messageRecord{Channel: thread.Channel, TS: thread.TS}
+		}
+		cur.Files = make([]string, len(uploaded))
+		for i, f := range uploaded {
+			cur.Files[i] = f.ID
+		}
+		return cur
+	})
+}
+
+// announcementFor returns the message release files are threaded under:
+// the tracked announcement rec, or posted when none is recorded.
+func announcementFor(rec *messageRecord, posted *postedMessage) *postedMessage {
+	if rec != nil {
+		return &postedMessage{Channel: rec.Channel, TS: rec.TS}
+	}
+	if posted == nil || posted.TS == "" {
+		return nil
+	}
+	return posted
+}
+
+// uploadFile reserves an upload URL, sends the file content to it and
+// returns the file ID.
+func (p *SlackPlugin) uploadFile(ctx context.Context, token string, f releaseFile) (string, error) {
+	var reserved getUploadURLResponse
+	if _, err := p.callAPI(ctx, token, "files.getUploadURLExternal", url.Values{
+		"filename": {f.Name},
+		"length":   {strconv.Itoa(len(f.Content))},
+	}, &reserved); err != nil {
+		return "", err
+	}
+	if !p.trustedUploadURL(reserved.UploadURL) {
+		return "", fmt.Errorf("slack returned an untrusted upload URL")
+	}
+
+	if err := p.rateLimiter().wait(ctx, []limitKey{{name: "upload", limit: uploadLimit}}); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", reserved.UploadURL, bytes.NewReader(f.Content))
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", f.Name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxAPIResponseSize))
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("upload of %s returned status %d", f.Name, resp.StatusCode)
+	}
+	return reserved.FileID, nil
+}
+
+// trustedUploadURL reports whether an upload URL points at Slack: an HTTPS
+// URL on an allowed Slack host, or the origin of the configured API.
+func (p *SlackPlugin) trustedUploadURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme == "https" && allowedSlackHosts[u.Hostname()] {
+		return true
+	}
+	api, err := url.Parse(p.apiURL(""))
+	return err == nil && u.Scheme == api.Scheme && u.Host == api.Host
+}
@@ -0,0 +1,256 @@
+// Package main provides tests for release file uploads.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// artifactSum is the SHA-256 of the test artifact content "artifact".
+const artifactSum = "c7c5c1d70c5dec4416ab6158afd0b223ef40c29b1dc1f97ed9428b94d4cadb1c"
+
+// fakeUploads records the files uploaded to the fake Slack.
+type fakeUploads struct {
+	mu       sync.Mutex
+	names    []string
+	contents map[string]string
+	complete []completeUploadRequest
+}
+
+// useFakeUploadAPI fakes chat.postMessage and the external upload flow.
+func useFakeUploadAPI(t *testing.T) *fakeUploads {
+	t.Helper()
+
+	uploads := &fakeUploads{contents: map[string]string{}}
+	var serverURL string
+	server := useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads.mu.Lock()
+		defer uploads.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/api/chat.postMessage":
+			writeJSON(w, map[string]any{"ok": true, "channel": "C123", "ts": "1700000000.000100"})
+		case r.URL.Path == "/api/files.getUploadURLExternal":
+			_ = r.ParseForm()
+			name := r.PostForm.Get("filename")
+			uploads.names = append(uploads.names, name)
+			writeJSON(w, map[string]any{"ok": true, "upload_url": serverURL + "/upload/" + name, "file_id": "F" + name})
+		case strings.HasPrefix(r.URL.Path, "/upload/"):
+			body, _ := io.ReadAll(r.Body)
+			uploads.contents[strings.TrimPrefix(r.URL.Path, "/upload/")] = string(body)
+		case r.URL.Path == "/api/files.completeUploadExternal":
+			var req completeUploadRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			uploads.complete = append(uploads.complete, req)
+			writeJSON(w, map[string]any{"ok": true})
+		default:
+			writeJSON(w, map[string]any{"ok": true})
+		}
+	}))
+	serverURL = server.URL
+	return uploads
+}
+
+// TestUploadReleaseFiles tests sharing release notes and the artifacts
+// manifest into the announcement thread.
+func TestUploadReleaseFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.tar.gz"), []byte("artifact"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	notes := strings.Repeat("All the changes. ", 200)
+	releaseCtx := plugin.ReleaseContext{Version: "1.4.0", TagName: "v1.4.0", ReleaseNotes: notes}
+
+	t.Run("notes and manifest", func(t *testing.T) {
+		uploads := useFakeUploadAPI(t)
+		config := map[string]any{
+			"bot_token":            "xoxb-1-2-3",
+			"channel":              "#releases",
+			"state_dir":            t.TempDir(),
+			"upload_release_notes": true,
+			"artifacts":            []any{filepath.Join(dir, "*.tar.gz")},
+		}
+
+		resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPostPublish, Config: config, Context: releaseCtx,
+		})
+		if err != nil || !resp.Success {
+			t.Fatalf("expected success, got %v / %+v", err, resp)
+		}
+
+		want := []string{"release-notes-1.4.0.md", "artifacts-1.4.0.json"}
+		if strings.Join(uploads.names, ",") != strings.Join(want, ",") {
+			t.Fatalf("expected uploads %v, got %v", want, uploads.names)
+		}
+		if uploads.contents["release-notes-1.4.0.md"] != notes {
+			t.Error("expected untruncated release notes")
+		}
+
+		var manifest artifactManifest
+		if err := json.Unmarshal([]byte(uploads.contents["artifacts-1.4.0.json"]), &manifest); err != nil {
+			t.Fatalf("invalid manifest: %v", err)
+		}
+		if len(manifest.Artifacts) != 1 || manifest.Artifacts[0].Size != 8 || manifest.Artifacts[0].SHA256 != artifactSum {
+			t.Errorf("unexpected manifest %+v", manifest)
+		}
+
+		if len(uploads.complete) != 1 {
+			t.Fatalf("expected one completeUploadExternal call, got %d", len(uploads.complete))
+		}
+		done := uploads.complete[0]
+		if done.ChannelID != "C123" || done.ThreadTS != "1700000000.000100" || len(done.Files) != 2 {
+			t.Errorf("expected files shared into the announcement thread, got %+v", done)
+		}
+	})
+
+	t.Run("shared once per release", func(t *testing.T) {
+		uploads := useFakeUploadAPI(t)
+		config := map[string]any{
+			"bot_token":            "xoxb-1-2-3",
+			"channel":              "#releases",
+			"state_dir":            t.TempDir(),
+			"upload_release_notes": true,
+			"artifacts":            []any{filepath.Join(dir, "*.tar.gz")},
+		}
+		for _, hook := range []plugin.Hook{plugin.HookPostPublish, plugin.HookOnSuccess} {
+			resp, err := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{Hook: hook, Config: config, Context: releaseCtx})
+			if err != nil || !resp.Success {
+				t.Fatalf("%s: expected success, got %v / %+v", hook, err, resp)
+			}
+		}
+		if len(uploads.complete) != 1 || len(uploads.names) != 2 {
+			t.Errorf("expected one completeUploadExternal call for two files, got %d for %v", len(uploads.complete), uploads.names)
+		}
+		rec, _ := readMessageRecord(config["state_dir"].(string), "1.4.0")
+		if rec == nil || len(rec.Files) != 2 {
+			t.Errorf("expected the shared files to be recorded, got %+v", rec)
+		}
+	})
+
+	t.Run("threads under the tracked announcement", func(t *testing.T) {
+		uploads := useFakeUploadAPI(t)
+		stateDir := t.TempDir()
+		if err := updateMessageRecord(stateDir, "1.4.0", func(*messageRecord) *messageRecord {
+			return &messageRecord{Channel: "C999", TS: "1.000001"}
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		resp, _ := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookOnSuccess,
+			Config:  map[string]any{"bot_token": "xoxb-1-2-3", "channel": "#releases", "state_dir": stateDir, "upload_release_notes": true},
+			Context: releaseCtx,
+		})
+		if !resp.Success {
+			t.Fatalf("expected success, got %s", resp.Error)
+		}
+		if len(uploads.complete) != 1 || uploads.complete[0].ChannelID != "C999" || uploads.complete[0].ThreadTS != "1.000001" {
+			t.Errorf("expected files in the tracked thread, got %+v", uploads.complete)
+		}
+	})
+
+	t.Run("upload failure does not fail the release", func(t *testing.T) {
+		useFakeSlackAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/files.getUploadURLExternal" {
+				writeJSON(w, map[string]any{"ok": false, "error": "missing_scope", "needed": "files:write"})
+				return
+			}
+			writeJSON(w, map[string]any{"ok": true, "channel": "C123", "ts": "1.1"})
+		}))
+
+		resp, _ := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    plugin.HookPostPublish,
+			Config:  map[string]any{"bot_token": "xoxb-1-2-3", "channel": "#releases", "state_dir": t.TempDir(), "upload_release_notes": true},
+			Context: releaseCtx,
+		})
+		if !resp.Success {
+			t.Errorf("expected success, got %s", resp.Error)
+		}
+	})
+
+	t.Run("dry run lists files", func(t *testing.T) {
+		resp, _ := (&SlackPlugin{}).Execute(context.Background(), plugin.ExecuteRequest{
+			Hook: plugin.HookPostPublish,
+			Config: map[string]any{
+				"bot_token": "xoxb-1-2-3", "channel": "#releases",
+				"upload_release_notes": true, "artifacts": []any{filepath.Join(dir, "*")},
+			},
+			Context: releaseCtx,
+			DryRun:  true,
+		})
+		files, _ := resp.Outputs["files"].([]string)
+		if len(files) != 2 {
+			t.Errorf("expected two files in dry-run outputs, got %v", resp.Outputs)
+		}
+	})
+}
+
+// TestBuildArtifactManifest tests manifest contents and ordering.
+func TestBuildArtifactManifest(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.zip", "a.zip"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("artifact"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "c.zip"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := buildArtifactManifest(
+		[]string{filepath.Join(dir, "*.zip"), filepath.Join(dir, "a.zip")},
+		plugin.ReleaseContext{Version: "1.0.0", TagName: "v1.0.0"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var manifest artifactManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if manifest.Version != "1.0.0" || len(manifest.Artifacts) != 2 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if !strings.HasSuffix(manifest.Artifacts[0].Name, "a.zip") || !strings.HasSuffix(manifest.Artifacts[1].Name, "b.zip") {
+		t.Errorf("expected sorted, de-duplicated artifacts, got %+v", manifest.Artifacts)
+	}
+	if got := manifest.Artifacts[0].SHA256; got != artifactSum {
+		t.Errorf("unexpected checksum %q", got)
+	}
+
+	if _, err := buildArtifactManifest([]string{"["}, plugin.ReleaseContext{}); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
+
+// TestTrustedUploadURL tests which upload URLs are accepted.
+func TestTrustedUploadURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://files.slack.com/upload/v1/abc": true,
+		"http://files.slack.com/upload/v1/abc":  false,
+		"https://evil.example.com/upload":       false,
+		"":                                      false,
+	}
+	p := &SlackPlugin{}
+	for raw, want := range tests {
+		if got := p.trustedUploadURL(raw); got != want {
+			t.Errorf("trustedUploadURL(%q) = %v, want %v", raw, got, want)
+		}
+	}
+
+	p.baseURL = "http://127.0.0.1:8080"
+	if !p.trustedUploadURL("http://127.0.0.1:8080/upload/F1") || p.trustedUploadURL("http://127.0.0.1:9090/upload/F1") {
+		t.Error("expected upload URLs on the base URL to be trusted")
+	}
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// warningCodePrefix marks validation entries that are advisory only.
+// Warnings are returned alongside errors but do not make the config invalid.
+const warningCodePrefix = "warning:"
+
+var (
+	// channelNamePattern matches Slack channel names, with or without the leading '#'.
+	channelNamePattern = regexp.MustCompile(`^#?[a-z0-9][a-z0-9._-]{0,79}$`)
+	// channelIDPattern matches Slack conversation IDs (public, private and DM).
+	channelIDPattern = regexp.MustCompile(`^[CGD][A-Z0-9]{8,}$`)
+	// directMessagePattern matches legacy "@username" webhook channel overrides.
+	directMessagePattern = regexp.MustCompile(`^@[a-z0-9][a-z0-9._-]{0,79}$`)
+	// emojiPattern matches Slack emoji short codes such as ":rocket:".
+	emojiPattern = regexp.MustCompile(`^:[a-z0-9_+'-]+:$`)
+	// userIDPattern matches Slack user IDs, optionally prefixed with '@'.
+	userIDPattern = regexp.MustCompile(`^@?[UW][A-Z0-9]{2,}$`)
+	// formattedMentionPattern matches mentions already in Slack's escaped form.
+	formattedMentionPattern = regexp.MustCompile(`^(<@[UW][A-Z0-9]+>|<!subteam\^S[A-Z0-9]+(\|[^>]+)?>|<!(here|channel|everyone)>)$`)
+)
+
+// specialMentions are broadcast mentions accepted in "@name" form.
+var specialMentions = map[string]string{
+	"@here":     "<!here>",
+	"@channel":  "<!channel>",
+	"@everyone": "<!everyone>",
+}
+
+// configSchema is the subset of JSON Schema enforced by Validate.
+type configSchema struct {
+	Type                 string                   `json:"type"`
+	Properties           map[string]*configSchema `json:"properties"`
+	AdditionalProperties *bool                    `json:"additionalProperties"`
+	Items                *configSchema            `json:"items"`
+	Required             []string                 `json:"required"`
+	Enum                 []any                    `json:"enum"`
+	Minimum              *float64                 `json:"minimum"`
+	// AdditionalSchema is the schema of unlisted properties, when
+	// additionalProperties is a schema rather than a boolean.
+	AdditionalSchema *configSchema `json:"-"`
+}
+
+// UnmarshalJSON decodes a schema whose additionalProperties is a boolean or a schema.
+func (s *configSchema) UnmarshalJSON(data []byte) error {
+	type plain configSchema
+	var raw struct {
+		plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = configSchema(raw.plain)
+	if len(raw.AdditionalProperties) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw.AdditionalProperties, &s.AdditionalProperties); err == nil {
+		return nil
+	}
+	s.AdditionalSchema = new(configSchema)
+	return json.Unmarshal(raw.AdditionalProperties, s.AdditionalSchema)
+}
+
+// parseConfigSchema decodes the schema advertised in GetInfo.
+func parseConfigSchema(raw string) (*configSchema, error) {
+	var schema configSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, fmt.Errorf("invalid config schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// validateSchema checks value against schema, reporting errors under path.
+// The top level requires no keys, since credentials may come from environment
+// variables; Validate checks them explicitly.
+func validateSchema(vb *helpers.ValidationBuilder, schema *configSchema, path string, value any) {
+	if schema == nil || value == nil {
+		return
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		vb.AddErrorWithCode(path,
+			fmt.Sprintf("%s must be of type %s, got %s", path, schema.Type, describeType(value)),
+			"type")
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		allowed := make([]string, len(schema.Enum))
+		for i, e := range schema.Enum {
+			allowed[i] = fmt.Sprint(e)
+		}
+		vb.AddErrorWithCode(path,
+			fmt.Sprintf("%s must be one of: %s", path, strings.Join(allowed, ", ")),
+			"enum")
+		return
+	}
+
+	if schema.Minimum != nil {
+		if n, ok := toFloat(value); ok && n < *schema.Minimum {
+			vb.AddErrorWithCode(path,
+				fmt.Sprintf("%s must be at least %v", path, *schema.Minimum),
+				"minimum")
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, k := range schema.Required {
+			if _, ok := v[k]; !ok {
+				fieldPath := joinPath(path, k)
+				vb.AddErrorWithCode(fieldPath, fieldPath+" is required", "required")
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fieldPath := joinPath(path, k)
+			prop, known := schema.Properties[k]
+			if !known {
+				switch {
+				case schema.AdditionalSchema != nil:
+					validateSchema(vb, schema.AdditionalSchema, fieldPath, v[k])
+				case schema.AdditionalProperties != nil && !*schema.AdditionalProperties:
+					vb.AddErrorWithCode(fieldPath, unknownFieldMessage(k, schema.Properties), "unknown_field")
+				}
+				continue
+			}
+			validateSchema(vb, prop, fieldPath, v[k])
+		}
+
+	case []any:
+		for i, item := range v {
+			validateSchema(vb, schema.Items, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+
+	case []string:
+		for i, item := range v {
+			validateSchema(vb, schema.Items, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	}
+}
+
+// matchesType reports whether value is of the given JSON Schema type.
+func matchesType(typ string, value any) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	case "array":
+		switch value.(type) {
+		case []any, []string:
+			return true
+		}
+		return false
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	default:
+		return true
+	}
+}
+
+// toFloat converts the numeric types produced by config decoders to float64.
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// describeType names the JSON type of a decoded config value.
+func describeType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []any, []string:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// inEnum reports whether value equals one of the allowed enum values.
+func inEnum(enum []any, value any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinPath appends a field name to a config path.
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// unknownFieldMessage describes an unknown key, suggesting the closest known key.
+func unknownFieldMessage(key string, known map[string]*configSchema) string {
+	best, bestDist := "", math.MaxInt
+	for k := range known {
+		if d := levenshtein(key, k); d < bestDist || (d == bestDist && k < best) {
+			best, bestDist = k, d
+		}
+	}
+	if best != "" && bestDist <= 3 {
+		return fmt.Sprintf("unknown field %q (did you mean %q?)", key, best)
+	}
+	return fmt.Sprintf("unknown field %q", key)
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// validateSemantics performs checks that JSON Schema cannot express.
+// Hard errors go to vb, advisory findings to warnings.
+func validateSemantics(vb, warnings *helpers.ValidationBuilder, config map[string]any) {
+	parser := helpers.NewConfigParser(config)
+
+	emoji, hasEmoji := config["icon_emoji"].(string)
+	iconURL, hasIconURL := config["icon_url"].(string)
+	if hasEmoji && emoji != "" && hasIconURL && iconURL != "" {
+		vb.AddErrorWithCode("icon_url", "icon_emoji and icon_url are mutually exclusive; set only one", "conflict")
+	}
+	if hasEmoji && emoji != "" && !emojiPattern.MatchString(emoji) {
+		vb.AddErrorWithCode("icon_emoji",
+			fmt.Sprintf("icon_emoji %q must be an emoji short code such as :rocket:", emoji),
+			"format")
+	}
+	if hasIconURL && iconURL != "" {
+		if err := validateIconURL(iconURL); err != nil {
+			vb.AddErrorWithCode("icon_url", err.Error(), "format")
+		}
+	}
+
+	for _, key := range []string{"channel", "prerelease_channel"} {
+		if channel, ok := config[key].(string); ok && channel != "" {
+			if err := validateChannel(channel); err != nil {
+				vb.AddErrorWithCode(key, err.Error(), "format")
+			}
+		}
+	}
+
+	for _, key := range templateConfigKeys {
+		if text, ok := config[key].(string); ok && text != "" {
+			if err := validateTemplate(key, text); err != nil {
+				vb.AddErrorWithCode(key, err.Error(), "template")
+			}
+		}
+	}
+	assets, _ := config["assets"].([]any)
+	for i, item := range assets {
+		a, _ := item.(map[string]any)
+		if text, ok := a["url"].(string); ok && text != "" {
+			path := fmt.Sprintf("assets[%d].url", i)
+			if err := validateTemplate(path, text); err != nil {
+				vb.AddErrorWithCode(path, err.Error(), "template")
+			}
+		}
+	}
+
+	validateQuietHours(vb, warnings, config)
+
+	if locale, ok := config["locale"].(string); ok && locale != "" {
+		validateLocaleField(vb, warnings, "locale", locale)
+	}
+	validateDestinations(vb, warnings, config)
+	validateThemes(vb, warnings, config)
+
+	if parser.GetBool("metrics", false) && !parser.GetBool("history", false) {
+		warnings.AddErrorWithCode("metrics",
+			"metrics are computed from the release history; enable history to record releases",
+			warningCodePrefix+"requires_history")
+	}
+	if !parser.GetBool("audit", false) {
+		for _, key := range []string{"audit_file", "audit_max_size_mb", "audit_max_files", "audit_hmac_key"} {
+			if parser.Has(key) {
+				warnings.AddErrorWithCode(key, key+" has no effect unless audit is enabled", warningCodePrefix+"requires_audit")
+			}
+		}
+	}
+
+	for i, m := range parser.GetStringSlice("mentions", nil) {
+		if err := validateMention(m); err != nil {
+			warnings.AddErrorWithCode(fmt.Sprintf("mentions[%d]", i), err.Error(), warningCodePrefix+"mention_format")
+		}
+	}
+}
+
+// botTokenOnlyKeys returns the enabled options that only take effect in bot-token mode.
+func botTokenOnlyKeys(cfg *Config) []string {
+	var keys []string
+	if cfg.Reactions {
+		keys = append(keys, "reactions")
+	}
+	if cfg.UploadReleaseNotes {
+		keys = append(keys, "upload_release_notes")
+	}
+	if len(cfg.Artifacts) > 0 {
+		keys = append(keys, "artifacts")
+	}
+	if cfg.ChannelTopicTemplate != "" {
+		keys = append(keys, "channel_topic_template")
+	}
+	if cfg.ChannelBookmarkTemplate != "" {
+		keys = append(keys, "channel_bookmark_template")
+	}
+	return keys
+}
+
+// validateLocaleField reports malformed locales as errors and unsupported
+// ones as warnings.
+func validateLocaleField(vb, warnings *helpers.ValidationBuilder, field, locale string) {
+	supported, err := validateLocale(locale)
+	if err != nil {
+		vb.AddErrorWithCode(field, err.Error(), "format")
+		return
+	}
+	if !supported {
+		warnings.AddErrorWithCode(field,
+			fmt.Sprintf("locale %q has no message catalog; English is used", locale),
+			warningCodePrefix+"locale")
+	}
+}
+
+// validateDestinations checks each additional destination. Without a bot
+// token every destination needs its own webhook.
+func validateDestinations(vb, warnings *helpers.ValidationBuilder, config map[string]any) {
+	items, _ := config["destinations"].([]any)
+	botToken := helpers.NewConfigParser(config).GetString("bot_token", "SLACK_BOT_TOKEN", "")
+	names := map[string]bool{}
+
+	for i, item := range items {
+		d, ok := item.(map[string]any)
+		if !ok {
+			continue // reported by the schema check
+		}
+		path := fmt.Sprintf("destinations[%d]", i)
+
+		webhook, _ := d["webhook"].(string)
+		if webhook == "" && botToken == "" {
+			vb.AddErrorWithCode(path+".webhook", "webhook is required for destinations unless bot_token is set", "required")
+		} else if webhook != "" {
+			if err := validateSlackWebhookURL(webhook); err != nil {
+				vb.AddErrorWithCode(path+".webhook", err.Error(), "format")
+			}
+		}
+		if channel, _ := d["channel"].(string); channel != "" {
+			if err := validateChannel(channel); err != nil {
+				vb.AddErrorWithCode(path+".channel", err.Error(), "format")
+			}
+		}
+		if locale, _ := d["locale"].(string); locale != "" {
+			validateLocaleField(vb, warnings, path+".locale", locale)
+		}
+		if name, _ := d["name"].(string); name != "" {
+			if names[name] {
+				vb.AddErrorWithCode(path+".name", fmt.Sprintf("destination name %q is used more than once", name), "conflict")
+			}
+			names[name] = true
+		}
+	}
+}
+
+// validateIconURL checks that an icon URL is an absolute HTTP(S) URL.
+func validateIconURL(iconURL string) error {
+	parsed, err := url.Parse(iconURL)
+	if err != nil {
+		return fmt.Errorf("icon_url is not a valid URL: %w", err)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return fmt.Errorf("icon_url must use http or https")
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("icon_url must be an absolute URL")
+	}
+	return nil
+}
+
+// validateChannel checks a channel name, channel ID or "@user" override.
+func validateChannel(channel string) error {
+	if channelIDPattern.MatchString(channel) ||
+		channelNamePattern.MatchString(channel) ||
+		directMessagePattern.MatchString(channel) {
+		return nil
+	}
+	return fmt.Errorf("channel %q must be a channel ID (C…) or a lowercase channel name of at most 80 letters, digits, '-', '_' or '.'", channel)
+}
+
+// validateMention checks that a mention will actually notify someone.
+func validateMention(m string) error {
+	if formattedMentionPattern.MatchString(m) || userIDPattern.MatchString(m) {
+		return nil
+	}
+	if _, ok := specialMentions[m]; ok {
+		return nil
+	}
+	return fmt.Errorf("mention %q is not a user ID (U…), <@U…>, <!subteam^S…>, @here, @channel or @everyone and will not notify anyone", m)
+}
+
+// mergeWarnings appends advisory entries to a validation response without affecting Valid.
+func mergeWarnings(resp *plugin.ValidateResponse, warnings *helpers.ValidationBuilder) *plugin.ValidateResponse {
+	resp.Errors = append(resp.Errors, warnings.Build().Errors...)
+	return resp
+}
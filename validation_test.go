@@ -0,0 +1,318 @@
+// Package main provides tests for configuration validation.
+package main
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestValidateDeep tests schema and semantic validation of the plugin config.
+func TestValidateDeep(t *testing.T) {
+	p := &SlackPlugin{}
+	ctx := context.Background()
+	_ = os.Unsetenv("SLACK_WEBHOOK_URL")
+
+	const webhook = "https://hooks.slack.com/services/T00000000/B00000000/TESTTOKEN"
+
+	tests := []struct {
+		name         string
+		config       map[string]any
+		wantValid    bool
+		wantField    string
+		wantCode     string
+		wantContains string
+	}{
+		{
+			name:         "unknown key with suggestion",
+			config:       map[string]any{"webhook": webhook, "notify_on_sucess": true},
+			wantField:    "notify_on_sucess",
+			wantCode:     "unknown_field",
+			wantContains: `did you mean "notify_on_success"`,
+		},
+		{
+			name:         "unknown key without suggestion",
+			config:       map[string]any{"webhook": webhook, "completely_unrelated": 1},
+			wantField:    "completely_unrelated",
+			wantCode:     "unknown_field",
+			wantContains: `unknown field "completely_unrelated"`,
+		},
+		{
+			name:         "non-string var",
+			config:       map[string]any{"webhook": webhook, "vars": map[string]any{"release_url": 1}},
+			wantField:    "vars.release_url",
+			wantCode:     "type",
+			wantContains: "must be of type string, got",
+		},
+		{
+			name:      "asset URL template",
+			config:    map[string]any{"webhook": webhook, "assets": []any{map[string]any{"url": "{{.Vars.cdn"}}},
+			wantField: "assets[0].url",
+			wantCode:  "template",
+		},
+		{
+			name:      "asset needs a URL",
+			config:    map[string]any{"webhook": webhook, "assets": []any{map[string]any{"name": "app.zip"}}},
+			wantField: "assets[0].url",
+			wantCode:  "required",
+		},
+		{
+			name:      "bot token without webhook",
+			config:    map[string]any{"bot_token": "xoxb-123", "channel": "#releases"},
+			wantValid: true,
+		},
+		{
+			name:         "non-boolean include_changelog",
+			config:       map[string]any{"webhook": webhook, "include_changelog": "yes"},
+			wantField:    "include_changelog",
+			wantCode:     "type",
+			wantContains: "must be of type boolean, got string",
+		},
+		{
+			name:      "mentions must be an array",
+			config:    map[string]any{"webhook": webhook, "mentions": "U123"},
+			wantField: "mentions",
+			wantCode:  "type",
+		},
+		{
+			name:      "mention items must be strings",
+			config:    map[string]any{"webhook": webhook, "mentions": []any{"U123", 42}},
+			wantField: "mentions[1]",
+			wantCode:  "type",
+		},
+		{
+			name:      "icon_emoji and icon_url conflict",
+			config:    map[string]any{"webhook": webhook, "icon_emoji": ":ship:", "icon_url": "https://example.com/i.png"},
+			wantField: "icon_url",
+			wantCode:  "conflict",
+		},
+		{
+			name:      "malformed icon_url",
+			config:    map[string]any{"webhook": webhook, "icon_url": "example.com/i.png"},
+			wantField: "icon_url",
+			wantCode:  "format",
+		},
+		{
+			name:      "malformed icon_emoji",
+			config:    map[string]any{"webhook": webhook, "icon_emoji": "rocket"},
+			wantField: "icon_emoji",
+			wantCode:  "format",
+		},
+		{
+			name:      "invalid channel name",
+			config:    map[string]any{"webhook": webhook, "channel": "#Release Notes"},
+			wantField: "channel",
+			wantCode:  "format",
+		},
+		{
+			name:      "channel ID",
+			config:    map[string]any{"webhook": webhook, "channel": "C0123456789"},
+			wantValid: true,
+		},
+		{
+			name:      "direct message override",
+			config:    map[string]any{"webhook": webhook, "channel": "@alice"},
+			wantValid: true,
+		},
+		{
+			name:      "unresolvable mention is a warning",
+			config:    map[string]any{"webhook": webhook, "mentions": []any{"U123", "@team"}},
+			wantValid: true,
+			wantField: "mentions[1]",
+			wantCode:  warningCodePrefix + "mention_format",
+		},
+		{
+			name:      "broken template",
+			config:    map[string]any{"webhook": webhook, "plan_template": "{{.Verison}}"},
+			wantField: "plan_template",
+			wantCode:  "template",
+		},
+		{
+			name:      "quiet hours with unknown timezone",
+			config:    map[string]any{"webhook": webhook, "quiet_hours": map[string]any{"timezone": "Mars/Olympus", "start": "22:00", "end": "07:00"}},
+			wantField: "quiet_hours",
+			wantCode:  "format",
+		},
+		{
+			name:      "quiet hours unknown mode",
+			config:    map[string]any{"webhook": webhook, "quiet_hours": map[string]any{"start": "22:00", "end": "07:00", "mode": "mute"}},
+			wantField: "quiet_hours.mode",
+			wantCode:  "enum",
+		},
+		{
+			name:      "scheduled quiet hours need a bot token",
+			config:    map[string]any{"webhook": webhook, "quiet_hours": map[string]any{"start": "22:00", "end": "07:00", "mode": "schedule"}},
+			wantValid: true,
+			wantField: "quiet_hours.mode",
+			wantCode:  warningCodePrefix + "requires_bot_token",
+		},
+		{
+			name:      "malformed locale",
+			config:    map[string]any{"webhook": webhook, "locale": "not a locale"},
+			wantField: "locale",
+			wantCode:  "format",
+		},
+		{
+			name:      "locale without catalog",
+			config:    map[string]any{"webhook": webhook, "locale": "sw"},
+			wantValid: true,
+			wantField: "locale",
+			wantCode:  warningCodePrefix + "locale",
+		},
+		{
+			name:      "destination needs a webhook",
+			config:    map[string]any{"webhook": webhook, "destinations": []any{map[string]any{"locale": "de"}}},
+			wantField: "destinations[0].webhook",
+			wantCode:  "required",
+		},
+		{
+			name: "duplicate destination name",
+			config: map[string]any{"webhook": webhook, "destinations": []any{
+				map[string]any{"webhook": webhook, "name": "leads"},
+				map[string]any{"webhook": webhook, "name": "leads"},
+			}},
+			wantField: "destinations[1].name",
+			wantCode:  "conflict",
+		},
+		{
+			name:      "destination with unknown key",
+			config:    map[string]any{"webhook": webhook, "destinations": []any{map[string]any{"webhook": webhook, "lang": "de"}}},
+			wantCode:  "unknown_field",
+			wantField: "destinations[0].lang",
+		},
+		{
+			name:      "unknown theme",
+			config:    map[string]any{"webhook": webhook, "themes": map[string]any{"hotfix": map[string]any{"color": "danger"}}},
+			wantField: "themes.hotfix",
+			wantCode:  "unknown_field",
+		},
+		{
+			name:      "invalid theme color",
+			config:    map[string]any{"webhook": webhook, "themes": map[string]any{"patch": map[string]any{"color": "blue"}}},
+			wantField: "themes.patch.color",
+			wantCode:  "format",
+		},
+		{
+			name:      "invalid theme title",
+			config:    map[string]any{"webhook": webhook, "themes": map[string]any{"major": map[string]any{"title": "{{.Version"}}},
+			wantField: "themes.major.title",
+			wantCode:  "template",
+		},
+		{
+			name:      "invalid prerelease channel",
+			config:    map[string]any{"webhook": webhook, "prerelease_channel": "#Releases RC"},
+			wantField: "prerelease_channel",
+			wantCode:  "format",
+		},
+		{
+			name:      "metrics without history",
+			config:    map[string]any{"webhook": webhook, "metrics": true},
+			wantValid: true,
+			wantField: "metrics",
+			wantCode:  warningCodePrefix + "requires_history",
+		},
+		{
+			name:      "invalid format",
+			config:    map[string]any{"webhook": webhook, "format": "markdown"},
+			wantField: "format",
+			wantCode:  "enum",
+		},
+		{
+			name: "valid full config",
+			config: map[string]any{
+				"webhook":           webhook,
+				"channel":           "#releases",
+				"username":          "ReleaseBot",
+				"icon_emoji":        ":ship:",
+				"notify_on_success": true,
+				"notify_on_error":   false,
+				"include_changelog": true,
+				"mentions":          []any{"<!subteam^S123ABC>", "@channel", "<@U123>"},
+			},
+			wantValid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Validate(ctx, tt.config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if resp.Valid != tt.wantValid {
+				t.Errorf("expected valid=%v, got valid=%v (%v)", tt.wantValid, resp.Valid, resp.Errors)
+			}
+
+			if tt.wantField == "" {
+				if len(resp.Errors) > 0 {
+					t.Errorf("expected no findings, got %v", resp.Errors)
+				}
+				return
+			}
+
+			for _, e := range resp.Errors {
+				if e.Field != tt.wantField {
+					continue
+				}
+				if e.Code != tt.wantCode {
+					t.Errorf("expected code %q for %s, got %q", tt.wantCode, tt.wantField, e.Code)
+				}
+				if tt.wantContains != "" && !strings.Contains(e.Message, tt.wantContains) {
+					t.Errorf("expected message to contain %q, got %q", tt.wantContains, e.Message)
+				}
+				return
+			}
+			t.Errorf("expected finding for field %q, got %v", tt.wantField, resp.Errors)
+		})
+	}
+}
+
+// TestValidateSchemaMatchesConfig ensures every parsed config key is declared in the schema.
+func TestValidateSchemaMatchesConfig(t *testing.T) {
+	p := &SlackPlugin{}
+	schema, err := parseConfigSchema(p.GetInfo().ConfigSchema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if schema.AdditionalProperties == nil || *schema.AdditionalProperties {
+		t.Error("expected schema to reject additional properties")
+	}
+	if len(schema.Required) > 0 {
+		t.Errorf("expected no required keys, since credentials may come from the environment, got %v", schema.Required)
+	}
+
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		key, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("expected schema to declare %q", key)
+		}
+	}
+}
+
+// TestLevenshtein tests the edit distance used for key suggestions.
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"notify_on_sucess", "notify_on_success", 1},
+		{"chanel", "channel", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}